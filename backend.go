@@ -0,0 +1,148 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SpeechBackend is a text-to-speech engine that VoiceSystem delegates to.
+// Every backend also implements DirectSpeaker, AudioSynthesizer, or both.
+type SpeechBackend interface {
+	// Name returns the backend identifier used in configuration (e.g., "say")
+	Name() string
+	// ListVoices returns the voices installed for this backend
+	ListVoices(ctx context.Context) ([]VoiceInfo, error)
+	// Capabilities describes what the backend supports
+	Capabilities() BackendCapabilities
+}
+
+// DirectSpeaker is implemented by backends that play speech themselves
+type DirectSpeaker interface {
+	// Speak speaks the (already sanitized) message and blocks until it is done or ctx is cancelled
+	Speak(ctx context.Context, message, voice string, opts SpeakOptions) error
+}
+
+// AudioSynthesizer is implemented by backends that can render speech to a WAV file
+type AudioSynthesizer interface {
+	// SynthesizeToFile writes the spoken message to path as WAV audio
+	SynthesizeToFile(ctx context.Context, message, voice string, opts SpeakOptions, path string) error
+}
+
+// SSMLTranslator is implemented by backends that can express SSML markup in their own input syntax.
+// Messages for other backends have their markup stripped.
+type SSMLTranslator interface {
+	// TranslateSSML renders the (already sanitized) document as the message to pass to Speak or SynthesizeToFile
+	TranslateSSML(doc ssmlDocument, opts SpeakOptions) string
+}
+
+// SpeakOptions holds prosody settings for a single utterance.
+// A zero value means "use the backend default".
+type SpeakOptions struct {
+	Rate     int    // Speaking rate in words per minute
+	Pitch    int    // Pitch on a 1-100 scale, 50 is neutral
+	Volume   int    // Volume as a percentage, 100 is full volume
+	Priority string // Notification priority ("low", "normal", "high")
+}
+
+// BackendCapabilities describes the features supported by a speech backend
+type BackendCapabilities struct {
+	Rate   bool // Honors SpeakOptions.Rate
+	Pitch  bool // Honors SpeakOptions.Pitch
+	Volume bool // Honors SpeakOptions.Volume
+
+	// Supported values of each setting; a zero range means the default range for the setting
+	RateRange   prosodyRange
+	PitchRange  prosodyRange
+	VolumeRange prosodyRange
+}
+
+// speechBackendFactories maps configuration names to backend constructors
+var speechBackendFactories = map[string]func() SpeechBackend{
+	"say":       newSayBackend,
+	"espeak-ng": newEspeakBackend,
+	"piper":     newPiperBackend,
+	"speechd":   newSpeechdBackend,
+	"http":      newHTTPBackend,
+	"bell":      newBellBackend,
+}
+
+// defaultSpeechBackend returns the backend name used when none is configured
+func defaultSpeechBackend() string {
+	if runtime.GOOS == "darwin" {
+		return "say"
+	}
+	return "espeak-ng"
+}
+
+// newSpeechBackend creates the backend registered under the given name
+func newSpeechBackend(name string) (SpeechBackend, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		name = defaultSpeechBackend()
+	}
+
+	factory, ok := speechBackendFactories[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown speech backend %q (available: %s)",
+			name, strings.Join(speechBackendNames(), ", "))
+	}
+	return factory(), nil
+}
+
+// speechBackendNames returns the registered backend names in sorted order
+func speechBackendNames() []string {
+	names := make([]string, 0, len(speechBackendFactories))
+	for name := range speechBackendFactories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// speechText renders the document for a backend, translating the markup when the backend supports it
+func speechText(backend SpeechBackend, doc ssmlDocument, opts SpeakOptions) string {
+	if translator, ok := backend.(SSMLTranslator); ok {
+		return translator.TranslateSSML(doc, opts)
+	}
+	return doc.PlainText()
+}
+
+// newSpeechCommand prepares a speech-related command to run in its own process group,
+// which is killed when ctx is done
+func newSpeechCommand(ctx context.Context, command string, args []string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, command, args...)
+	configureProcessGroup(cmd)
+	// Do not wait forever for grandchildren that inherited stdout or stderr
+	cmd.WaitDelay = time.Second
+	return cmd
+}
+
+// speechCommandOutput runs a speech-related command and returns its standard output
+func speechCommandOutput(ctx context.Context, command string, args []string) ([]byte, error) {
+	return newSpeechCommand(ctx, command, args).Output()
+}
+
+// runSpeechCommand runs a speech-related command, capturing stderr for error reporting.
+// If input is not empty it is written to the command's standard input.
+func runSpeechCommand(ctx context.Context, command string, args []string, input string) error {
+	cmd := newSpeechCommand(ctx, command, args)
+	if input != "" {
+		cmd.Stdin = strings.NewReader(input)
+	}
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+	if err != nil {
+		err = fmt.Errorf("%s command failed: %w, stderr: %s", command, err, stderr.String())
+	}
+	debugLogVoiceCommand(command, args, input, err)
+	return err
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fakeBackend is an in-memory SpeechBackend for tests
+type fakeBackend struct {
+	name     string
+	voices   []VoiceInfo
+	caps     BackendCapabilities
+	speakErr error
+	spoken   []fakeUtterance
+}
+
+type fakeUtterance struct {
+	Message string
+	Voice   string
+	Options SpeakOptions
+}
+
+func (f *fakeBackend) Name() string {
+	if f.name == "" {
+		return "fake"
+	}
+	return f.name
+}
+
+func (f *fakeBackend) ListVoices(ctx context.Context) ([]VoiceInfo, error) {
+	return f.voices, nil
+}
+
+func (f *fakeBackend) Speak(ctx context.Context, message, voice string, opts SpeakOptions) error {
+	f.spoken = append(f.spoken, fakeUtterance{Message: message, Voice: voice, Options: opts})
+	return f.speakErr
+}
+
+func (f *fakeBackend) Capabilities() BackendCapabilities {
+	return f.caps
+}
+
+// TestNewSpeechBackend tests backend lookup by configuration name
+func TestNewSpeechBackend(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+		wantErr  bool
+	}{
+		{name: "empty_uses_default", input: "", expected: defaultSpeechBackend()},
+		{name: "say", input: "say", expected: "say"},
+		{name: "espeak", input: "espeak-ng", expected: "espeak-ng"},
+		{name: "case_insensitive", input: " SAY ", expected: "say"},
+		{name: "unknown", input: "festival", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			backend, err := newSpeechBackend(tt.input)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("newSpeechBackend(%q) expected error, got %s", tt.input, backend.Name())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("newSpeechBackend(%q) unexpected error: %v", tt.input, err)
+			}
+			if backend.Name() != tt.expected {
+				t.Errorf("newSpeechBackend(%q) = %s, want %s", tt.input, backend.Name(), tt.expected)
+			}
+		})
+	}
+}
+
+// TestVoiceSystem_RefreshVoicesFromBackend tests that the catalog comes from the backend
+func TestVoiceSystem_RefreshVoicesFromBackend(t *testing.T) {
+	backend := &fakeBackend{
+		voices: []VoiceInfo{
+			{Name: "en-us", Language: "en", Locale: "en_US"},
+			{Name: "ja", Language: "ja", Locale: "ja_JP"},
+		},
+	}
+	vs := &VoiceSystem{backend: backend, availableVoices: make(map[string]VoiceInfo)}
+
+	if err := vs.refreshVoices(context.Background()); err != nil {
+		t.Fatalf("refreshVoices() unexpected error: %v", err)
+	}
+	if got := vs.SelectVoice("", "ja"); got != "ja" {
+		t.Errorf("SelectVoice(\"\", \"ja\") = %q, want %q", got, "ja")
+	}
+}
+
+// TestVoiceSystem_SpeakDelegates tests that Speak sanitizes and passes the priority's persona options
+func TestVoiceSystem_SpeakDelegates(t *testing.T) {
+	tests := []struct {
+		priority string
+		options  SpeakOptions
+	}{
+		{priority: "high", options: SpeakOptions{Rate: 200, Priority: "high"}},
+		{priority: "normal", options: SpeakOptions{Priority: "normal"}},
+		{priority: "low", options: SpeakOptions{Rate: 150, Priority: "low"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.priority, func(t *testing.T) {
+			backend := &fakeBackend{caps: BackendCapabilities{Rate: true}}
+			vs := &VoiceSystem{backend: backend}
+			persona, err := vs.Persona("", tt.priority)
+			if err != nil {
+				t.Fatalf("Persona() unexpected error: %v", err)
+			}
+
+			if _, err := vs.Speak(context.Background(), "Build\tdone\a", "Alex", persona.SpeakOptions(tt.priority), ""); err != nil {
+				t.Fatalf("Speak() unexpected error: %v", err)
+			}
+			if len(backend.spoken) != 1 {
+				t.Fatalf("expected 1 utterance, got %d", len(backend.spoken))
+			}
+			got := backend.spoken[0]
+			if got.Message != "Build done" || got.Voice != "Alex" || got.Options != tt.options {
+				t.Errorf("Speak() delegated %+v, want message %q voice %q options %+v",
+					got, "Build done", "Alex", tt.options)
+			}
+		})
+	}
+}
+
+// TestVoiceSystem_SpeakBackendError tests that backend failures are returned
+func TestVoiceSystem_SpeakBackendError(t *testing.T) {
+	backendErr := errors.New("device busy")
+	vs := &VoiceSystem{backend: &fakeBackend{speakErr: backendErr}}
+
+	if _, err := vs.Speak(context.Background(), "hello", "", SpeakOptions{Priority: "normal"}, ""); !errors.Is(err, backendErr) {
+		t.Errorf("Speak() error = %v, want %v", err, backendErr)
+	}
+
+	vs = &VoiceSystem{}
+	if _, err := vs.Speak(context.Background(), "hello", "", SpeakOptions{Priority: "normal"}, ""); !errors.Is(err, errNoSpeechBackend) {
+		t.Errorf("Speak() without backend error = %v, want %v", err, errNoSpeechBackend)
+	}
+}
+
+// installStubCommand writes an executable shell script named name into a temp
+// directory that is prepended to PATH. Each invocation appends its arguments,
+// one per line followed by "--", to the returned log file.
+func installStubCommand(t *testing.T, name, body string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, name+".log")
+	script := "#!/bin/sh\n" +
+		"for arg in \"$@\"; do printf '%s\\n' \"$arg\" >> \"" + logPath + "\"; done\n" +
+		"printf -- '--\\n' >> \"" + logPath + "\"\n" +
+		body + "\n"
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(script), 0o755); err != nil {
+		t.Fatalf("failed to write stub %s: %v", name, err)
+	}
+
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	return logPath
+}
+
+// readStubInvocations returns the argument lists recorded by a stub command
+func readStubInvocations(t *testing.T, logPath string) [][]string {
+	t.Helper()
+
+	data, err := os.ReadFile(logPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		t.Fatalf("failed to read stub log: %v", err)
+	}
+
+	var calls [][]string
+	current := []string{}
+	for _, line := range strings.Split(strings.TrimSuffix(string(data), "\n"), "\n") {
+		if line == "--" {
+			calls = append(calls, current)
+			current = []string{}
+			continue
+		}
+		current = append(current, line)
+	}
+	return calls
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+)
+
+// bellBackendName is the name of the bell backend, which signals but cannot speak
+const bellBackendName = "bell"
+
+// bellBackend rings the terminal bell; it is the last resort when no speech backend works
+type bellBackend struct {
+	ttyPath string
+	stderr  io.Writer
+}
+
+// newBellBackend creates a terminal bell backend
+func newBellBackend() SpeechBackend {
+	return &bellBackend{ttyPath: "/dev/tty", stderr: os.Stderr}
+}
+
+// Name returns the backend identifier
+func (b *bellBackend) Name() string {
+	return bellBackendName
+}
+
+// Capabilities returns the features supported by the bell (none)
+func (b *bellBackend) Capabilities() BackendCapabilities {
+	return BackendCapabilities{}
+}
+
+// ListVoices returns no voices; the bell cannot speak
+func (b *bellBackend) ListVoices(ctx context.Context) ([]VoiceInfo, error) {
+	return nil, nil
+}
+
+// Speak rings the bell on the controlling terminal.
+// Standard output carries the MCP protocol, so the bell falls back to stderr without a terminal.
+func (b *bellBackend) Speak(ctx context.Context, message, voice string, opts SpeakOptions) error {
+	if tty, err := os.OpenFile(b.ttyPath, os.O_WRONLY, 0); err == nil {
+		defer tty.Close()
+		if _, err := tty.Write([]byte("\a")); err == nil {
+			return nil
+		}
+	}
+
+	if _, err := b.stderr.Write([]byte("\a")); err != nil {
+		return fmt.Errorf("failed to ring terminal bell: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// TestBellBackend_Speak tests ringing the bell on stderr when there is no terminal
+func TestBellBackend_Speak(t *testing.T) {
+	var stderr bytes.Buffer
+	backend := &bellBackend{ttyPath: filepath.Join(t.TempDir(), "no-tty"), stderr: &stderr}
+
+	if err := backend.Speak(context.Background(), "Build done", "", SpeakOptions{}); err != nil {
+		t.Fatalf("Speak() unexpected error: %v", err)
+	}
+	if stderr.String() != "\a" {
+		t.Errorf("stderr = %q, want a bell character", stderr.String())
+	}
+}
+
+// TestHandleNotifyVoice_BellFallback tests that a notification delivered only by the bell is reported as degraded
+func TestHandleNotifyVoice_BellFallback(t *testing.T) {
+	vs := &VoiceSystem{
+		backend:         &fakeBackend{name: "say", speakErr: errors.New("voice missing")},
+		fallbacks:       []SpeechBackend{&fakeBackend{name: bellBackendName}},
+		availableVoices: map[string]VoiceInfo{},
+	}
+	langDetect := &LanguageDetector{autoDetect: true, defaultLanguage: "en"}
+
+	result, err := handleNotifyVoice(context.Background(), newTestToolRequest(map[string]any{"message": "Build done"}),
+		vs, langDetect, nil, newTestNotifier(), newSpeechJobTracker())
+	if err != nil || result.IsError {
+		t.Fatalf("handleNotifyVoice() = %+v, %v", result, err)
+	}
+	if text := resultText(t, result); !strings.Contains(text, "Backend: bell") {
+		t.Errorf("response %q does not report the bell", text)
+	}
+	warning, _ := mcp.AsTextContent(result.Content[len(result.Content)-1])
+	if len(result.Content) != 2 || warning == nil || !strings.Contains(warning.Text, "speech_unavailable") {
+		t.Errorf("result content = %+v, want a speech_unavailable warning", result.Content)
+	}
+}
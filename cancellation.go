@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"sync"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// requestIDMetaKey is the _meta field used to pass the JSON-RPC request ID to tool handlers.
+// mcp-go only hands the ID to hooks, so the BeforeCallTool hook copies it into the request.
+const requestIDMetaKey = "voice-notify/requestId"
+
+// speechJobTracker maps MCP request IDs to the speech jobs they started,
+// so notifications/cancelled can stop the right job
+type speechJobTracker struct {
+	mu   sync.Mutex
+	jobs map[string]*SpeechJob
+}
+
+// newSpeechJobTracker creates an empty tracker
+func newSpeechJobTracker() *speechJobTracker {
+	return &speechJobTracker{jobs: make(map[string]*SpeechJob)}
+}
+
+// Track associates a job with a request ID until the job finishes
+func (t *speechJobTracker) Track(requestID string, job *SpeechJob) {
+	if requestID == "" {
+		return
+	}
+
+	t.mu.Lock()
+	t.jobs[requestID] = job
+	t.mu.Unlock()
+
+	go func() {
+		<-job.done
+		t.mu.Lock()
+		if t.jobs[requestID] == job {
+			delete(t.jobs, requestID)
+		}
+		t.mu.Unlock()
+	}()
+}
+
+// Lookup returns the unfinished job started by a request, if any
+func (t *speechJobTracker) Lookup(requestID string) *SpeechJob {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.jobs[requestID]
+}
+
+// tagRequestID is a BeforeCallTool hook that records the request ID in the request's _meta
+func tagRequestID(ctx context.Context, id any, request *mcp.CallToolRequest) {
+	if request.Params.Meta == nil {
+		request.Params.Meta = &mcp.Meta{}
+	}
+	if request.Params.Meta.AdditionalFields == nil {
+		request.Params.Meta.AdditionalFields = make(map[string]any)
+	}
+	request.Params.Meta.AdditionalFields[requestIDMetaKey] = mcp.NewRequestId(id).String()
+}
+
+// requestIDFromMeta returns the request ID recorded by tagRequestID
+func requestIDFromMeta(request mcp.CallToolRequest) string {
+	if request.Params.Meta == nil {
+		return ""
+	}
+	requestID, _ := request.Params.Meta.AdditionalFields[requestIDMetaKey].(string)
+	return requestID
+}
+
+// handleCancelledNotification stops the speech job of a request cancelled by the client.
+// The stdio transport reads one message at a time, so a notify_voice call that waits for its speech
+// is finished before its cancellation is read; only jobs queued with wait=false can be stopped.
+func handleCancelledNotification(voiceSystem *VoiceSystem, tracker *speechJobTracker) func(ctx context.Context, notification mcp.JSONRPCNotification) {
+	return func(ctx context.Context, notification mcp.JSONRPCNotification) {
+		id, ok := notification.Params.AdditionalFields["requestId"]
+		if !ok {
+			return
+		}
+
+		requestID := mcp.NewRequestId(id).String()
+		job := tracker.Lookup(requestID)
+		if job == nil {
+			debugLog("Cancelled request %s has no pending speech job", requestID)
+			return
+		}
+
+		debugLog("Request %s cancelled, stopping speech job %s", requestID, job.ID)
+		voiceSystem.CancelJob(job)
+	}
+}
@@ -76,7 +76,23 @@ func debugLogEnvironment() {
 	}
 
 	debugLog("Environment Variables:")
+	debugLog("  VOICE_NOTIFY_BACKEND: %s", os.Getenv("VOICE_NOTIFY_BACKEND"))
+	debugLog("  VOICE_NOTIFY_PLAYER: %s", os.Getenv("VOICE_NOTIFY_PLAYER"))
+	debugLog("  VOICE_NOTIFY_FALLBACK: %s", os.Getenv("VOICE_NOTIFY_FALLBACK"))
+	debugLog("  VOICE_NOTIFY_EARCON: %s", os.Getenv("VOICE_NOTIFY_EARCON"))
+	debugLog("  VOICE_NOTIFY_PERSONAS: %s", os.Getenv("VOICE_NOTIFY_PERSONAS"))
+	debugLog("  VOICE_NOTIFY_PRIORITY_PERSONAS: %s", os.Getenv("VOICE_NOTIFY_PRIORITY_PERSONAS"))
+	debugLog("  VOICE_NOTIFY_EARCON_FILES: %s", os.Getenv("VOICE_NOTIFY_EARCON_FILES"))
+	debugLog("  VOICE_NOTIFY_LEXICON: %s", os.Getenv("VOICE_NOTIFY_LEXICON"))
+	debugLog("  VOICE_NOTIFY_NORMALIZE: %s", os.Getenv("VOICE_NOTIFY_NORMALIZE"))
+	debugLog("  VOICE_NOTIFY_VERBALIZE: %s", os.Getenv("VOICE_NOTIFY_VERBALIZE"))
 	debugLog("  VOICE_NOTIFY_DEFAULT_VOICE: %s", os.Getenv("VOICE_NOTIFY_DEFAULT_VOICE"))
+	debugLog("  VOICE_NOTIFY_STRICT_VOICE: %s", os.Getenv("VOICE_NOTIFY_STRICT_VOICE"))
+	debugLog("  VOICE_NOTIFY_VOICE_PREFERENCES: %s", os.Getenv("VOICE_NOTIFY_VOICE_PREFERENCES"))
+	debugLog("  VOICE_NOTIFY_VOICE_CACHE_TTL: %s", os.Getenv("VOICE_NOTIFY_VOICE_CACHE_TTL"))
+	debugLog("  VOICE_NOTIFY_MISSING_VOICE: %s", os.Getenv("VOICE_NOTIFY_MISSING_VOICE"))
+	debugLog("  VOICE_NOTIFY_TEXT_SINK: %s", os.Getenv("VOICE_NOTIFY_TEXT_SINK"))
+	debugLog("  VOICE_NOTIFY_VOICE_METADATA: %s", os.Getenv("VOICE_NOTIFY_VOICE_METADATA"))
 	debugLog("  VOICE_NOTIFY_DEFAULT_LANGUAGE: %s", os.Getenv("VOICE_NOTIFY_DEFAULT_LANGUAGE"))
 	debugLog("  VOICE_NOTIFY_AUTO_DETECT_LANGUAGE: %s", os.Getenv("VOICE_NOTIFY_AUTO_DETECT_LANGUAGE"))
 	debugLog("  VOICE_NOTIFY_AUTO_NOTIFY: %s", os.Getenv("VOICE_NOTIFY_AUTO_NOTIFY"))
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// earconSampleRate is the sample rate of synthesized earcons
+const earconSampleRate = 22050
+
+// earconTone is one note of an earcon. A zero frequency is a rest.
+type earconTone struct {
+	Frequency float64       // Fundamental frequency in Hz
+	Duration  time.Duration // Length of the note including its decay
+	Gain      float64       // Peak amplitude from 0 to 1
+}
+
+// builtinEarcons are the synthesized chimes, keyed by status or priority.
+// Statuses use distinct melodic shapes so they can be told apart before the words start:
+// rising for success, falling for failure, repeated for warning, and an upward
+// inflection for questions.
+var builtinEarcons = map[string][]earconTone{
+	"success": {
+		{Frequency: 1046.5, Duration: 90 * time.Millisecond, Gain: 0.5},  // C6
+		{Frequency: 1318.5, Duration: 90 * time.Millisecond, Gain: 0.5},  // E6
+		{Frequency: 1568.0, Duration: 220 * time.Millisecond, Gain: 0.5}, // G6
+	},
+	"failure": {
+		{Frequency: 493.9, Duration: 160 * time.Millisecond, Gain: 0.6}, // B4
+		{Frequency: 349.2, Duration: 320 * time.Millisecond, Gain: 0.6}, // F4
+	},
+	"warning": {
+		{Frequency: 880.0, Duration: 110 * time.Millisecond, Gain: 0.55}, // A5
+		{Duration: 50 * time.Millisecond},
+		{Frequency: 880.0, Duration: 110 * time.Millisecond, Gain: 0.55},
+		{Duration: 50 * time.Millisecond},
+		{Frequency: 880.0, Duration: 110 * time.Millisecond, Gain: 0.55},
+	},
+	"question": {
+		{Frequency: 659.3, Duration: 120 * time.Millisecond, Gain: 0.5}, // E5
+		{Frequency: 987.8, Duration: 260 * time.Millisecond, Gain: 0.5}, // B5
+	},
+	"high": {
+		{Frequency: 1318.5, Duration: 100 * time.Millisecond, Gain: 0.6}, // E6
+		{Frequency: 1318.5, Duration: 200 * time.Millisecond, Gain: 0.6},
+	},
+	"normal": {
+		{Frequency: 1046.5, Duration: 250 * time.Millisecond, Gain: 0.45}, // C6
+	},
+	"low": {
+		{Frequency: 523.3, Duration: 250 * time.Millisecond, Gain: 0.3}, // C5
+	},
+}
+
+// earconSet resolves earcon names to audio files, preferring user-supplied sounds
+type earconSet struct {
+	files map[string]string
+}
+
+// newEarconSetFromEnv creates the earcons configured by VOICE_NOTIFY_EARCON and
+// VOICE_NOTIFY_EARCON_FILES. It returns nil when earcons are disabled.
+func newEarconSetFromEnv() *earconSet {
+	if !getEnvBool("VOICE_NOTIFY_EARCON", true) {
+		return nil
+	}
+	return &earconSet{files: parseEarconFiles(getEnv("VOICE_NOTIFY_EARCON_FILES", ""))}
+}
+
+// parseEarconFiles parses a comma-separated list of custom sound files
+// Format: "success=/path/to/done.wav,failure=~/sounds/fail.aiff"
+func parseEarconFiles(list string) map[string]string {
+	files := make(map[string]string)
+
+	for _, entry := range strings.Split(list, ",") {
+		name, path, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		name = strings.ToLower(strings.TrimSpace(name))
+		path = strings.TrimSpace(path)
+		if !ok || name == "" || path == "" {
+			if entry = strings.TrimSpace(entry); entry != "" {
+				log.Printf("Invalid VOICE_NOTIFY_EARCON_FILES entry: %q", entry)
+			}
+			continue
+		}
+
+		if strings.HasPrefix(path, "~/") {
+			if home, err := os.UserHomeDir(); err == nil {
+				path = filepath.Join(home, path[2:])
+			}
+		}
+		files[name] = path
+	}
+
+	return files
+}
+
+// earconName picks the earcon for a notification; a known status takes precedence over priority
+func earconName(priority, status string) string {
+	if _, ok := builtinEarcons[status]; ok {
+		return status
+	}
+	if _, ok := builtinEarcons[priority]; ok {
+		return priority
+	}
+	return "normal"
+}
+
+// File returns a playable file for the named earcon.
+// Built-in earcons are rendered to a temporary WAV file that cleanup removes.
+func (e *earconSet) File(name string) (path string, cleanup func(), err error) {
+	if custom, ok := e.files[name]; ok {
+		return custom, func() {}, nil
+	}
+
+	tones, ok := builtinEarcons[name]
+	if !ok {
+		return "", nil, fmt.Errorf("unknown earcon %q", name)
+	}
+
+	tmp, err := os.CreateTemp("", "voice-notify-earcon-*.wav")
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create earcon file: %w", err)
+	}
+	defer tmp.Close()
+
+	if err := writeWAV(tmp, synthesizeTones(tones, earconSampleRate), earconSampleRate); err != nil {
+		_ = os.Remove(tmp.Name())
+		return "", nil, fmt.Errorf("failed to write earcon: %w", err)
+	}
+	return tmp.Name(), func() { _ = os.Remove(tmp.Name()) }, nil
+}
+
+// synthesizeTones renders the tones as 16-bit mono samples.
+// Each note is a sine with a quieter octave overtone, a short linear attack
+// and an exponential decay, which sounds like a soft chime without clicks.
+func synthesizeTones(tones []earconTone, sampleRate int) []int16 {
+	const attack = 0.005 // seconds
+
+	var samples []int16
+	for _, tone := range tones {
+		count := int(tone.Duration.Seconds() * float64(sampleRate))
+		length := float64(count) / float64(sampleRate)
+
+		for i := 0; i < count; i++ {
+			if tone.Frequency <= 0 {
+				samples = append(samples, 0)
+				continue
+			}
+
+			t := float64(i) / float64(sampleRate)
+			envelope := math.Exp(-5 * t / length)
+			if t < attack {
+				envelope *= t / attack
+			}
+			// Fade the last millisecond to zero so consecutive notes do not click
+			if tail := float64(count-1-i) / float64(sampleRate); tail < 0.001 {
+				envelope *= tail / 0.001
+			}
+
+			wave := math.Sin(2*math.Pi*tone.Frequency*t) + 0.3*math.Sin(4*math.Pi*tone.Frequency*t)
+			value := tone.Gain * envelope * wave / 1.3
+			samples = append(samples, int16(math.MaxInt16*max(-1, min(1, value))))
+		}
+	}
+
+	return samples
+}
+
+// writeWAV writes samples as a 16-bit mono PCM WAV file
+func writeWAV(w io.Writer, samples []int16, sampleRate int) error {
+	const (
+		channels      = 1
+		bitsPerSample = 16
+	)
+	dataSize := uint32(len(samples) * bitsPerSample / 8)
+	blockAlign := uint16(channels * bitsPerSample / 8)
+
+	var buf bytes.Buffer
+	buf.WriteString("RIFF")
+	_ = binary.Write(&buf, binary.LittleEndian, 36+dataSize)
+	buf.WriteString("WAVE")
+
+	buf.WriteString("fmt ")
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(16)) // fmt chunk size
+	_ = binary.Write(&buf, binary.LittleEndian, uint16(1))  // PCM
+	_ = binary.Write(&buf, binary.LittleEndian, uint16(channels))
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(sampleRate))
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(sampleRate)*uint32(blockAlign))
+	_ = binary.Write(&buf, binary.LittleEndian, blockAlign)
+	_ = binary.Write(&buf, binary.LittleEndian, uint16(bitsPerSample))
+
+	buf.WriteString("data")
+	_ = binary.Write(&buf, binary.LittleEndian, dataSize)
+	_ = binary.Write(&buf, binary.LittleEndian, samples)
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
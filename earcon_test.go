@@ -0,0 +1,132 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// TestEarconName tests choosing the earcon from status and priority
+func TestEarconName(t *testing.T) {
+	tests := []struct {
+		priority string
+		status   string
+		expected string
+	}{
+		{priority: "normal", status: "success", expected: "success"},
+		{priority: "high", status: "failure", expected: "failure"},
+		{priority: "low", status: "question", expected: "question"},
+		{priority: "high", status: "", expected: "high"},
+		{priority: "low", status: "", expected: "low"},
+		{priority: "", status: "", expected: "normal"},
+		{priority: "urgent", status: "done", expected: "normal"},
+	}
+
+	for _, tt := range tests {
+		if got := earconName(tt.priority, tt.status); got != tt.expected {
+			t.Errorf("earconName(%q, %q) = %q, want %q", tt.priority, tt.status, got, tt.expected)
+		}
+	}
+}
+
+// TestParseEarconFiles tests parsing custom earcon sound files
+func TestParseEarconFiles(t *testing.T) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Skip("no home directory")
+	}
+
+	files := parseEarconFiles(" success=/sounds/done.wav, Failure = ~/fail.aiff,broken,=x.wav")
+	expected := map[string]string{
+		"success": "/sounds/done.wav",
+		"failure": filepath.Join(home, "fail.aiff"),
+	}
+	if !reflect.DeepEqual(files, expected) {
+		t.Errorf("parseEarconFiles() = %v, want %v", files, expected)
+	}
+}
+
+// TestWriteWAV tests the RIFF header of synthesized earcons
+func TestWriteWAV(t *testing.T) {
+	samples := synthesizeTones(builtinEarcons["success"], earconSampleRate)
+	if len(samples) == 0 {
+		t.Fatal("synthesizeTones() returned no samples")
+	}
+	// 90ms + 90ms + 220ms at 22050 Hz
+	if want := 1984 + 1984 + 4851; len(samples) != want {
+		t.Errorf("synthesized %d samples, want %d", len(samples), want)
+	}
+
+	var buf bytes.Buffer
+	if err := writeWAV(&buf, samples, earconSampleRate); err != nil {
+		t.Fatalf("writeWAV() unexpected error: %v", err)
+	}
+	data := buf.Bytes()
+
+	if string(data[0:4]) != "RIFF" || string(data[8:12]) != "WAVE" || string(data[36:40]) != "data" {
+		t.Fatalf("invalid WAV header: %q", data[:44])
+	}
+	if size := binary.LittleEndian.Uint32(data[4:8]); int(size) != len(data)-8 {
+		t.Errorf("RIFF size = %d, want %d", size, len(data)-8)
+	}
+	if rate := binary.LittleEndian.Uint32(data[24:28]); rate != earconSampleRate {
+		t.Errorf("sample rate = %d, want %d", rate, earconSampleRate)
+	}
+	if size := binary.LittleEndian.Uint32(data[40:44]); int(size) != 2*len(samples) {
+		t.Errorf("data size = %d, want %d", size, 2*len(samples))
+	}
+
+	// Notes start and end silent so they do not click
+	if samples[0] != 0 || samples[len(samples)-1] != 0 {
+		t.Errorf("first/last sample = %d/%d, want 0", samples[0], samples[len(samples)-1])
+	}
+}
+
+// TestVoiceSystem_SpeakPlaysEarcon tests that the earcon is played before the message
+func TestVoiceSystem_SpeakPlaysEarcon(t *testing.T) {
+	custom := filepath.Join(t.TempDir(), "done.wav")
+	if err := os.WriteFile(custom, []byte("custom chime"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	backend := &fakeBackend{}
+	player := &fakePlayer{}
+	vs := &VoiceSystem{
+		backend: backend,
+		player:  player,
+		earcons: &earconSet{files: map[string]string{"success": custom}},
+	}
+
+	if _, err := vs.Speak(context.Background(), "Build done", "", SpeakOptions{Priority: "normal"}, "success"); err != nil {
+		t.Fatalf("Speak() unexpected error: %v", err)
+	}
+	if len(player.played) != 1 || player.played[0] != custom {
+		t.Errorf("played %q, want the custom success earcon", player.played)
+	}
+
+	if _, err := vs.Speak(context.Background(), "Tests failed", "", SpeakOptions{Priority: "high"}, "failure"); err != nil {
+		t.Fatalf("Speak() unexpected error: %v", err)
+	}
+	if len(player.played) != 2 || !bytes.HasPrefix([]byte(player.content[1]), []byte("RIFF")) {
+		t.Fatalf("played %q, want a synthesized failure earcon", player.played)
+	}
+	if _, err := os.Stat(player.played[1]); !os.IsNotExist(err) {
+		t.Errorf("temporary earcon file %s was not removed", player.played[1])
+	}
+	if len(backend.spoken) != 2 {
+		t.Errorf("spoke %d messages, want 2", len(backend.spoken))
+	}
+
+	// Speech goes ahead without a chime when the earcon cannot be played
+	vs.player = nil
+	if _, err := vs.Speak(context.Background(), "Build done", "", SpeakOptions{Priority: "normal"}, "success"); err != nil {
+		t.Fatalf("Speak() without player unexpected error: %v", err)
+	}
+	if len(backend.spoken) != 3 {
+		t.Errorf("spoke %d messages, want 3", len(backend.spoken))
+	}
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// espeakLanguageAliases maps espeak language codes to the codes used by LanguageDetector
+var espeakLanguageAliases = map[string]string{
+	"cmn": "zh",
+	"yue": "zh",
+}
+
+// espeakBackend speaks using the espeak-ng command
+type espeakBackend struct {
+	command string
+}
+
+// newEspeakBackend creates a backend for the espeak-ng command
+func newEspeakBackend() SpeechBackend {
+	return &espeakBackend{command: getEnv("VOICE_NOTIFY_ESPEAK_COMMAND", "espeak-ng")}
+}
+
+// Name returns the backend identifier
+func (b *espeakBackend) Name() string {
+	return "espeak-ng"
+}
+
+// Capabilities returns the features supported by espeak-ng
+func (b *espeakBackend) Capabilities() BackendCapabilities {
+	// -s accepts 80-450 words per minute, -p 0-99 and -a an amplitude up to 200
+	return BackendCapabilities{
+		Rate: true, Pitch: true, Volume: true,
+		RateRange:   prosodyRange{Min: 80, Max: 450},
+		PitchRange:  prosodyRange{Min: 1, Max: 99},
+		VolumeRange: prosodyRange{Min: 1, Max: 200},
+	}
+}
+
+// CatalogFingerprint identifies the installed espeak-ng binary for the voice catalog cache
+func (b *espeakBackend) CatalogFingerprint() string {
+	return commandFingerprint(b.command)
+}
+
+// ListVoices runs 'espeak-ng --voices' and parses the installed voices
+func (b *espeakBackend) ListVoices(ctx context.Context) ([]VoiceInfo, error) {
+	args := []string{"--voices"}
+	debugLogVoiceCommand(b.command, args, "", nil)
+	output, err := speechCommandOutput(ctx, b.command, args)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get voices: %w", err)
+	}
+
+	debugLog("Parsing espeak-ng voice list output (%d bytes)", len(output))
+	return parseEspeakVoices(string(output)), nil
+}
+
+// Speak executes espeak-ng with the given voice, passing the message on stdin
+func (b *espeakBackend) Speak(ctx context.Context, message, voice string, opts SpeakOptions) error {
+	return runSpeechCommand(ctx, b.command, espeakArgs(voice, opts), message)
+}
+
+// SynthesizeToFile runs 'espeak-ng -w' to write WAV audio to path
+func (b *espeakBackend) SynthesizeToFile(ctx context.Context, message, voice string, opts SpeakOptions, path string) error {
+	args := append([]string{"-w", path}, espeakArgs(voice, opts)...)
+	return runSpeechCommand(ctx, b.command, args, message)
+}
+
+// espeakArgs builds the voice and prosody arguments for espeak-ng.
+// The message is read from stdin, so it can never be taken for an option.
+func espeakArgs(voice string, opts SpeakOptions) []string {
+	args := []string{}
+
+	if voice != "" {
+		args = append(args, "-v", voice)
+	}
+
+	// -s: words per minute, -p: pitch 0-99, -a: amplitude 0-200 (100 is normal)
+	if opts.Rate > 0 {
+		args = append(args, "-s", strconv.Itoa(opts.Rate))
+	}
+	if opts.Pitch > 0 {
+		args = append(args, "-p", strconv.Itoa(min(opts.Pitch, 99)))
+	}
+	if opts.Volume > 0 {
+		args = append(args, "-a", strconv.Itoa(opts.Volume))
+	}
+
+	return append(args, "--stdin")
+}
+
+// parseEspeakVoices parses the output of 'espeak-ng --voices'
+// Format: "Pty Language Age/Gender VoiceName File Other Languages"
+func parseEspeakVoices(output string) []VoiceInfo {
+	var voices []VoiceInfo
+
+	for _, line := range strings.Split(output, "\n") {
+		// Example: " 2  en-us           --/M      English_(America)  gmw/en-US            (en 3)"
+		parts := strings.Fields(line)
+		if len(parts) < 5 || parts[0] == "Pty" {
+			continue
+		}
+		if _, err := strconv.Atoi(parts[0]); err != nil {
+			continue
+		}
+
+		code := parts[1]
+		locale := normalizeLocale(code)
+		lang := strings.Split(locale, "_")[0]
+		if alias, ok := espeakLanguageAliases[lang]; ok {
+			lang = alias
+		}
+
+		voices = append(voices, VoiceInfo{
+			Name:     code,
+			Language: lang,
+			Locale:   locale,
+		})
+	}
+
+	return voices
+}
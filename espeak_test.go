@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const espeakVoicesOutput = `Pty Language       Age/Gender VoiceName          File                 Other Languages
+ 5  af              --/M      Afrikaans          gmw/af
+ 5  cmn             --/M      Chinese_(Mandarin,_latin_as_English) sit/cmn              (zh-cmn 5)(zh 5)
+ 2  en-gb           --/M      English_(Great_Britain) gmw/en               (en 2)
+ 2  en-us           --/M      English_(America)  gmw/en-US            (en 3)
+ 5  es-419          --/M      Spanish_(Latin_America) roa/es-419           (es-mx 6)
+ 5  ja              --/M      Japanese           jpx/ja
+`
+
+// TestParseEspeakVoices tests parsing of 'espeak-ng --voices' output
+func TestParseEspeakVoices(t *testing.T) {
+	voices := parseEspeakVoices(espeakVoicesOutput)
+	expected := []VoiceInfo{
+		{Name: "af", Language: "af", Locale: "af"},
+		{Name: "cmn", Language: "zh", Locale: "cmn"},
+		{Name: "en-gb", Language: "en", Locale: "en_GB"},
+		{Name: "en-us", Language: "en", Locale: "en_US"},
+		{Name: "es-419", Language: "es", Locale: "es_419"},
+		{Name: "ja", Language: "ja", Locale: "ja"},
+	}
+
+	if !reflect.DeepEqual(voices, expected) {
+		t.Errorf("parseEspeakVoices() = %+v, want %+v", voices, expected)
+	}
+}
+
+// TestEspeakBackend_ListVoices tests the voice catalog using a stub espeak-ng
+func TestEspeakBackend_ListVoices(t *testing.T) {
+	installStubCommand(t, "espeak-ng", "cat <<'EOF'\n"+espeakVoicesOutput+"EOF")
+
+	voices, err := newEspeakBackend().ListVoices(context.Background())
+	if err != nil {
+		t.Fatalf("ListVoices() unexpected error: %v", err)
+	}
+	if len(voices) != 6 {
+		t.Errorf("ListVoices() returned %d voices, want 6", len(voices))
+	}
+}
+
+// TestEspeakBackend_Speak tests argument mapping using a stub espeak-ng
+func TestEspeakBackend_Speak(t *testing.T) {
+	tests := []struct {
+		name     string
+		voice    string
+		opts     SpeakOptions
+		expected []string
+	}{
+		{
+			name:     "defaults",
+			expected: []string{"--stdin"},
+		},
+		{
+			name:     "voice_and_rate",
+			voice:    "en-gb",
+			opts:     SpeakOptions{Rate: 200},
+			expected: []string{"-v", "en-gb", "-s", "200", "--stdin"},
+		},
+		{
+			name:     "full_prosody",
+			opts:     SpeakOptions{Rate: 150, Pitch: 100, Volume: 80},
+			expected: []string{"-s", "150", "-p", "99", "-a", "80", "--stdin"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdinPath := filepath.Join(t.TempDir(), "stdin")
+			logPath := installStubCommand(t, "espeak-ng", `cat > "`+stdinPath+`"`)
+
+			if err := newEspeakBackend().(*espeakBackend).Speak(context.Background(), "-Build done", tt.voice, tt.opts); err != nil {
+				t.Fatalf("Speak() unexpected error: %v", err)
+			}
+
+			calls := readStubInvocations(t, logPath)
+			if len(calls) != 1 || !reflect.DeepEqual(calls[0], tt.expected) {
+				t.Errorf("espeak-ng invoked with %q, want %q", calls, tt.expected)
+			}
+			if input, err := os.ReadFile(stdinPath); err != nil || string(input) != "-Build done" {
+				t.Errorf("espeak-ng stdin = %q (%v), want the message", input, err)
+			}
+		})
+	}
+}
+
+// TestEspeakBackend_SpeakFailure tests that a failing espeak-ng reports stderr
+func TestEspeakBackend_SpeakFailure(t *testing.T) {
+	installStubCommand(t, "espeak-ng", "echo 'no audio device' >&2; exit 1")
+
+	err := newEspeakBackend().(*espeakBackend).Speak(context.Background(), "hello", "", SpeakOptions{})
+	if err == nil {
+		t.Fatal("Speak() expected error from failing espeak-ng")
+	}
+	if got := err.Error(); !strings.Contains(got, "no audio device") {
+		t.Errorf("Speak() error %q does not include stderr", got)
+	}
+}
+
+// TestEspeakBackend_SynthesizeToFile tests WAV output arguments using a stub espeak-ng
+func TestEspeakBackend_SynthesizeToFile(t *testing.T) {
+	logPath := installStubCommand(t, "espeak-ng", "exit 0")
+
+	backend := newEspeakBackend().(*espeakBackend)
+	if err := backend.SynthesizeToFile(context.Background(), "hello", "ja", SpeakOptions{Rate: 150}, "/tmp/out.wav"); err != nil {
+		t.Fatalf("SynthesizeToFile() unexpected error: %v", err)
+	}
+
+	expected := []string{"-w", "/tmp/out.wav", "-v", "ja", "-s", "150", "--stdin"}
+	if calls := readStubInvocations(t, logPath); len(calls) != 1 || !reflect.DeepEqual(calls[0], expected) {
+		t.Errorf("espeak-ng invoked with %q, want %q", calls, expected)
+	}
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net"
+	"os/exec"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// breakerThreshold is the number of consecutive failures that opens a backend's circuit
+	breakerThreshold = 3
+	// breakerCooldown is how long a backend with an open circuit is skipped
+	breakerCooldown = time.Minute
+	// defaultRetryBackoff is the delay before the first retry; it doubles for each retry
+	defaultRetryBackoff = 250 * time.Millisecond
+)
+
+// transientCommandPattern matches the stderr of speech commands that failed for a passing reason,
+// such as an audio device in use; other exit errors (unknown voice, bad arguments) are permanent
+var transientCommandPattern = regexp.MustCompile(`(?i)busy|temporarily unavailable|try again|timed? ?out|connection refused|interrupted system call`)
+
+// circuitBreaker skips backends that failed repeatedly until a cooldown has passed.
+// The zero value is ready to use.
+type circuitBreaker struct {
+	mu        sync.Mutex
+	failures  map[string]int
+	openUntil map[string]time.Time
+	now       func() time.Time
+}
+
+// Allow reports whether the backend may be tried.
+// After the cooldown one attempt is let through; another failure opens the circuit again.
+func (cb *circuitBreaker) Allow(name string) bool {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	return !cb.currentTime().Before(cb.openUntil[name])
+}
+
+// RecordSuccess closes the backend's circuit
+func (cb *circuitBreaker) RecordSuccess(name string) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	delete(cb.failures, name)
+	delete(cb.openUntil, name)
+}
+
+// RecordFailure counts a failed delivery and opens the circuit at the threshold
+func (cb *circuitBreaker) RecordFailure(name string) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if cb.failures == nil {
+		cb.failures = make(map[string]int)
+		cb.openUntil = make(map[string]time.Time)
+	}
+
+	cb.failures[name]++
+	if cb.failures[name] >= breakerThreshold {
+		cb.openUntil[name] = cb.currentTime().Add(breakerCooldown)
+		log.Printf("%s backend failed %d times in a row, skipping it for %v", name, cb.failures[name], breakerCooldown)
+	}
+}
+
+// currentTime returns the breaker's clock, which tests may replace
+func (cb *circuitBreaker) currentTime() time.Time {
+	if cb.now != nil {
+		return cb.now()
+	}
+	return time.Now()
+}
+
+// parseFallbackBackends creates the fallback backends from a comma-separated list.
+// Unknown names and the primary backend itself are skipped; "none" disables fallback.
+func parseFallbackBackends(list, primary string) []SpeechBackend {
+	var backends []SpeechBackend
+	seen := map[string]bool{primary: true}
+
+	for _, name := range strings.Split(list, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" || name == "none" || seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		backend, err := newSpeechBackend(name)
+		if err != nil {
+			log.Printf("Invalid VOICE_NOTIFY_FALLBACK entry: %v", err)
+			continue
+		}
+		backends = append(backends, backend)
+	}
+
+	return backends
+}
+
+// isTransientSpeechError reports whether retrying the same backend might succeed,
+// e.g. when the audio device is busy or a TTS server is briefly unavailable
+func isTransientSpeechError(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var statusErr *httpStatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode >= 500 || statusErr.StatusCode == 429
+	}
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return transientCommandPattern.MatchString(err.Error())
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os/exec"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// TestCircuitBreaker tests opening, cooldown, and reset of a backend circuit
+func TestCircuitBreaker(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	cb := &circuitBreaker{now: func() time.Time { return now }}
+
+	for i := 1; i < breakerThreshold; i++ {
+		cb.RecordFailure("say")
+		if !cb.Allow("say") {
+			t.Fatalf("circuit opened after %d failures, threshold is %d", i, breakerThreshold)
+		}
+	}
+
+	cb.RecordFailure("say")
+	if cb.Allow("say") {
+		t.Fatal("circuit should be open after reaching the threshold")
+	}
+	if !cb.Allow("espeak-ng") {
+		t.Error("other backends should not be affected")
+	}
+
+	// After the cooldown one attempt is allowed; failing again reopens the circuit
+	now = now.Add(breakerCooldown)
+	if !cb.Allow("say") {
+		t.Fatal("circuit should allow a trial after the cooldown")
+	}
+	cb.RecordFailure("say")
+	if cb.Allow("say") {
+		t.Fatal("circuit should reopen when the trial fails")
+	}
+
+	cb.RecordSuccess("say")
+	if !cb.Allow("say") {
+		t.Error("circuit should close after a success")
+	}
+}
+
+// TestIsTransientSpeechError tests which failures are retried
+func TestIsTransientSpeechError(t *testing.T) {
+	exitErr := exec.Command("sh", "-c", "exit 1").Run()
+	busyErr := runSpeechCommand(context.Background(), "sh", []string{"-c", "echo 'Device or resource busy' >&2; exit 1"}, "")
+	voiceErr := runSpeechCommand(context.Background(), "sh", []string{"-c", "echo 'Voice Bogus not found.' >&2; exit 1"}, "")
+
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{name: "command exit status", err: exitErr, expected: false},
+		{name: "audio device busy", err: busyErr, expected: true},
+		{name: "unknown voice", err: voiceErr, expected: false},
+		{name: "server unavailable", err: &httpStatusError{StatusCode: 503}, expected: true},
+		{name: "rate limited", err: &httpStatusError{StatusCode: 429}, expected: true},
+		{name: "bad request", err: &httpStatusError{StatusCode: 400}, expected: false},
+		{name: "network timeout", err: &net.DNSError{IsTimeout: true}, expected: true},
+		{name: "command not found", err: exec.ErrNotFound, expected: false},
+		{name: "no player", err: errNoAudioPlayer, expected: false},
+		{name: "cancelled", err: context.Canceled, expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isTransientSpeechError(tt.err); got != tt.expected {
+				t.Errorf("isTransientSpeechError(%v) = %v, want %v", tt.err, got, tt.expected)
+			}
+		})
+	}
+}
+
+// TestParseFallbackBackends tests building the fallback chain from configuration
+func TestParseFallbackBackends(t *testing.T) {
+	tests := []struct {
+		list     string
+		expected []string
+	}{
+		{list: "espeak-ng, bell", expected: []string{"espeak-ng", "bell"}},
+		{list: "say,bogus,BELL,bell", expected: []string{"bell"}},
+		{list: "none", expected: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.list, func(t *testing.T) {
+			var names []string
+			for _, backend := range parseFallbackBackends(tt.list, "say") {
+				names = append(names, backend.Name())
+			}
+			if !reflect.DeepEqual(names, tt.expected) {
+				t.Errorf("parseFallbackBackends(%q) = %v, want %v", tt.list, names, tt.expected)
+			}
+		})
+	}
+}
+
+// TestVoiceSystem_SpeakFallback tests falling back, retrying, and skipping failed backends
+func TestVoiceSystem_SpeakFallback(t *testing.T) {
+	primary := &fakeBackend{name: "say", speakErr: &httpStatusError{StatusCode: 503, Status: "503 Service Unavailable"}}
+	backup := &fakeBackend{name: "espeak-ng"}
+	vs := &VoiceSystem{
+		backend:      primary,
+		fallbacks:    []SpeechBackend{backup},
+		retries:      2,
+		retryBackoff: time.Millisecond,
+	}
+
+	delivered, err := vs.Speak(context.Background(), "Build done", "Kyoko", SpeakOptions{Priority: "normal"}, "")
+	if err != nil {
+		t.Fatalf("Speak() unexpected error: %v", err)
+	}
+	if delivered != "espeak-ng" {
+		t.Errorf("delivered by %q, want espeak-ng", delivered)
+	}
+	if len(primary.spoken) != 3 {
+		t.Errorf("primary tried %d times, want 3 (1 attempt + 2 retries)", len(primary.spoken))
+	}
+	if backup.spoken[0].Voice != "" {
+		t.Errorf("fallback got voice %q, want its default voice", backup.spoken[0].Voice)
+	}
+
+	// After repeated failures the primary is skipped entirely
+	for i := 1; i < breakerThreshold; i++ {
+		_, _ = vs.Speak(context.Background(), "Build done", "", SpeakOptions{Priority: "normal"}, "")
+	}
+	tried := len(primary.spoken)
+	if _, err := vs.Speak(context.Background(), "Build done", "", SpeakOptions{Priority: "normal"}, ""); err != nil {
+		t.Fatalf("Speak() unexpected error: %v", err)
+	}
+	if len(primary.spoken) != tried {
+		t.Errorf("primary was tried with an open circuit")
+	}
+}
+
+// TestVoiceSystem_SpeakFallbackErrors tests that all failures are reported and cancellation stops the chain
+func TestVoiceSystem_SpeakFallbackErrors(t *testing.T) {
+	primaryErr := errors.New("voice missing")
+	backupErr := errors.New("device busy")
+	backup := &fakeBackend{name: "bell", speakErr: backupErr}
+	vs := &VoiceSystem{
+		backend:   &fakeBackend{name: "say", speakErr: primaryErr},
+		fallbacks: []SpeechBackend{backup},
+	}
+
+	_, err := vs.Speak(context.Background(), "hello", "", SpeakOptions{Priority: "normal"}, "")
+	if !errors.Is(err, primaryErr) || !errors.Is(err, backupErr) {
+		t.Errorf("Speak() error = %v, want both backend errors", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	backup.spoken = nil
+	if _, err := vs.Speak(ctx, "hello", "", SpeakOptions{Priority: "normal"}, ""); err == nil {
+		t.Error("Speak() expected error for a cancelled context")
+	}
+	if len(backup.spoken) != 0 {
+		t.Error("fallback was used after cancellation")
+	}
+}
+
+// TestVoiceSystem_SpeakPermanentError tests that a permanent command failure falls back without retries
+func TestVoiceSystem_SpeakPermanentError(t *testing.T) {
+	modelErr := runSpeechCommand(context.Background(), "sh", []string{"-c", "echo 'Unable to find voice model' >&2; exit 1"}, "")
+	primary := &fakeBackend{name: "piper", speakErr: modelErr}
+	backup := &fakeBackend{name: "espeak-ng"}
+	vs := &VoiceSystem{
+		backend:      primary,
+		fallbacks:    []SpeechBackend{backup},
+		retries:      2,
+		retryBackoff: time.Millisecond,
+	}
+
+	delivered, err := vs.Speak(context.Background(), "Build done", "", SpeakOptions{Priority: "normal"}, "")
+	if err != nil || delivered != "espeak-ng" {
+		t.Fatalf("Speak() = %q, %v, want delivery by espeak-ng", delivered, err)
+	}
+	if len(primary.spoken) != 1 {
+		t.Errorf("primary tried %d times, want 1 for a permanent error", len(primary.spoken))
+	}
+}
+
+// TestVoiceSystem_SpeakFallbackFitsOptions tests that each fallback gets options within its own ranges
+func TestVoiceSystem_SpeakFallbackFitsOptions(t *testing.T) {
+	primary := &fakeBackend{
+		name:     "say",
+		caps:     BackendCapabilities{Rate: true, Pitch: true, Volume: true, RateRange: prosodyRange{Min: 90, Max: 500}},
+		speakErr: errors.New("voice missing"),
+	}
+	backup := &fakeBackend{
+		name: "espeak-ng",
+		caps: BackendCapabilities{Rate: true, Volume: true, RateRange: prosodyRange{Min: 80, Max: 450}, VolumeRange: prosodyRange{Min: 1, Max: 200}},
+	}
+	vs := &VoiceSystem{backend: primary, fallbacks: []SpeechBackend{backup}}
+
+	if _, err := vs.Speak(context.Background(), "Build done", "", SpeakOptions{Rate: 500, Pitch: 70, Volume: 90, Priority: "high"}, ""); err != nil {
+		t.Fatalf("Speak() unexpected error: %v", err)
+	}
+	expected := SpeakOptions{Rate: 450, Volume: 90, Priority: "high"}
+	if len(backup.spoken) != 1 || backup.spoken[0].Options != expected {
+		t.Errorf("fallback spoken = %+v, want options %+v", backup.spoken, expected)
+	}
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// httpTTSDefaultRate is the speaking rate that corresponds to speed 1.0
+const httpTTSDefaultRate = 175
+
+// httpBackend synthesizes speech with an OpenAI-compatible /v1/audio/speech endpoint
+type httpBackend struct {
+	baseURL   string
+	model     string
+	apiKeyEnv string
+	voices    []VoiceInfo
+	client    *http.Client
+}
+
+// httpSpeechRequest is the JSON body of a /v1/audio/speech request
+type httpSpeechRequest struct {
+	Model          string  `json:"model"`
+	Input          string  `json:"input"`
+	Voice          string  `json:"voice"`
+	Speed          float64 `json:"speed,omitempty"`
+	ResponseFormat string  `json:"response_format"`
+}
+
+// httpStatusError is returned when the TTS server answers with a non-2xx status
+type httpStatusError struct {
+	StatusCode int
+	Status     string
+	Detail     string
+}
+
+func (e *httpStatusError) Error() string {
+	return fmt.Sprintf("speech request failed: %s: %s", e.Status, e.Detail)
+}
+
+// newHTTPBackend creates an HTTP TTS backend from environment configuration
+func newHTTPBackend() SpeechBackend {
+	return &httpBackend{
+		baseURL:   getEnv("VOICE_NOTIFY_HTTP_TTS_URL", "http://localhost:8880"),
+		model:     getEnv("VOICE_NOTIFY_HTTP_TTS_MODEL", "tts-1"),
+		apiKeyEnv: getEnv("VOICE_NOTIFY_HTTP_TTS_API_KEY_ENV", "OPENAI_API_KEY"),
+		voices:    parseHTTPVoices(getEnv("VOICE_NOTIFY_HTTP_TTS_VOICES", "alloy,echo,fable,onyx,nova,shimmer")),
+		client:    &http.Client{Timeout: 60 * time.Second},
+	}
+}
+
+// Name returns the backend identifier
+func (b *httpBackend) Name() string {
+	return "http"
+}
+
+// Capabilities returns the features supported by the HTTP TTS endpoint
+func (b *httpBackend) Capabilities() BackendCapabilities {
+	// Speed 0.25-4.0 relative to httpTTSDefaultRate
+	return BackendCapabilities{Rate: true, RateRange: prosodyRange{Min: 44, Max: 700}}
+}
+
+// ListVoices returns the configured voice list
+func (b *httpBackend) ListVoices(ctx context.Context) ([]VoiceInfo, error) {
+	return b.voices, nil
+}
+
+// SynthesizeToFile requests audio from the server and writes it to path
+func (b *httpBackend) SynthesizeToFile(ctx context.Context, message, voice string, opts SpeakOptions, path string) error {
+	audio, err := b.synthesize(ctx, message, voice, opts)
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(path, audio, 0o600); err != nil {
+		return fmt.Errorf("failed to write audio file: %w", err)
+	}
+	return nil
+}
+
+// synthesize POSTs the message to the speech endpoint and returns the audio bytes
+func (b *httpBackend) synthesize(ctx context.Context, message, voice string, opts SpeakOptions) ([]byte, error) {
+	if voice == "" {
+		if len(b.voices) == 0 {
+			return nil, fmt.Errorf("no HTTP TTS voices configured")
+		}
+		voice = b.voices[0].Name
+	}
+
+	request := httpSpeechRequest{
+		Model:          b.model,
+		Input:          message,
+		Voice:          voice,
+		ResponseFormat: "wav",
+	}
+	if opts.Rate > 0 {
+		// OpenAI-compatible servers accept speed between 0.25 and 4.0
+		speed := float64(opts.Rate) / httpTTSDefaultRate
+		request.Speed = max(0.25, min(4.0, speed))
+	}
+
+	body, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode speech request: %w", err)
+	}
+
+	url := httpSpeechURL(b.baseURL)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create speech request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if apiKey := os.Getenv(b.apiKeyEnv); b.apiKeyEnv != "" && apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+
+	debugLog("HTTP TTS request - URL: %s, Model: %s, Voice: %s, Speed: %v", url, b.model, voice, request.Speed)
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("speech request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		detail, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, &httpStatusError{StatusCode: resp.StatusCode, Status: resp.Status, Detail: strings.TrimSpace(string(detail))}
+	}
+
+	audio, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read speech response: %w", err)
+	}
+	if len(audio) == 0 {
+		return nil, fmt.Errorf("speech response was empty")
+	}
+	return audio, nil
+}
+
+// httpSpeechURL builds the speech endpoint from a base URL with or without the /v1 suffix
+func httpSpeechURL(baseURL string) string {
+	baseURL = strings.TrimSuffix(baseURL, "/")
+	if strings.HasSuffix(baseURL, "/v1") {
+		return baseURL + "/audio/speech"
+	}
+	return baseURL + "/v1/audio/speech"
+}
+
+// parseHTTPVoices parses a comma-separated voice list with optional locales
+// Format: "alloy,nova:en_US,jf_alpha:ja_JP"
+func parseHTTPVoices(list string) []VoiceInfo {
+	var voices []VoiceInfo
+
+	for _, entry := range strings.Split(list, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		name, locale, _ := strings.Cut(entry, ":")
+		voice := VoiceInfo{Name: strings.TrimSpace(name)}
+		if locale = strings.TrimSpace(locale); locale != "" {
+			voice.Locale = normalizeLocale(locale)
+			voice.Language = strings.Split(voice.Locale, "_")[0]
+		}
+		voices = append(voices, voice)
+	}
+
+	return voices
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestParseHTTPVoices tests parsing of the configured voice list
+func TestParseHTTPVoices(t *testing.T) {
+	voices := parseHTTPVoices(" alloy, nova:en-us ,,jf_alpha:ja_JP")
+	expected := []VoiceInfo{
+		{Name: "alloy"},
+		{Name: "nova", Language: "en", Locale: "en_US"},
+		{Name: "jf_alpha", Language: "ja", Locale: "ja_JP"},
+	}
+
+	if !reflect.DeepEqual(voices, expected) {
+		t.Errorf("parseHTTPVoices() = %+v, want %+v", voices, expected)
+	}
+}
+
+// TestHTTPSpeechURL tests endpoint construction from base URLs
+func TestHTTPSpeechURL(t *testing.T) {
+	tests := map[string]string{
+		"http://localhost:8880":      "http://localhost:8880/v1/audio/speech",
+		"http://localhost:8880/":     "http://localhost:8880/v1/audio/speech",
+		"https://api.example.com/v1": "https://api.example.com/v1/audio/speech",
+	}
+
+	for base, expected := range tests {
+		if got := httpSpeechURL(base); got != expected {
+			t.Errorf("httpSpeechURL(%q) = %q, want %q", base, got, expected)
+		}
+	}
+}
+
+// TestHTTPBackend_SynthesizeToFile tests the speech request against an httptest server
+func TestHTTPBackend_SynthesizeToFile(t *testing.T) {
+	var received httpSpeechRequest
+	var authHeader, path string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		authHeader = r.Header.Get("Authorization")
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "audio/wav")
+		_, _ = w.Write([]byte("RIFF-audio"))
+	}))
+	defer server.Close()
+
+	t.Setenv("TEST_TTS_KEY", "secret")
+
+	backend := &httpBackend{
+		baseURL:   server.URL,
+		model:     "kokoro",
+		apiKeyEnv: "TEST_TTS_KEY",
+		voices:    parseHTTPVoices("af_heart:en_US"),
+		client:    server.Client(),
+	}
+
+	wavPath := filepath.Join(t.TempDir(), "out.wav")
+	if err := backend.SynthesizeToFile(context.Background(), "Build done", "", SpeakOptions{Rate: 1000}, wavPath); err != nil {
+		t.Fatalf("SynthesizeToFile() unexpected error: %v", err)
+	}
+
+	if path != "/v1/audio/speech" {
+		t.Errorf("request path = %q, want /v1/audio/speech", path)
+	}
+	if authHeader != "Bearer secret" {
+		t.Errorf("Authorization = %q, want bearer token from TEST_TTS_KEY", authHeader)
+	}
+	expected := httpSpeechRequest{Model: "kokoro", Input: "Build done", Voice: "af_heart", Speed: 4.0, ResponseFormat: "wav"}
+	if received != expected {
+		t.Errorf("request body = %+v, want %+v", received, expected)
+	}
+
+	audio, err := os.ReadFile(wavPath)
+	if err != nil || string(audio) != "RIFF-audio" {
+		t.Errorf("audio file = %q (%v), want server response", audio, err)
+	}
+}
+
+// TestHTTPBackend_SynthesizeError tests that server errors are reported without writing audio
+func TestHTTPBackend_SynthesizeError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "" {
+			t.Errorf("unexpected Authorization header when API key is unset")
+		}
+		http.Error(w, "unknown voice", http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	backend := &httpBackend{
+		baseURL:   server.URL + "/v1",
+		model:     "tts-1",
+		apiKeyEnv: "VOICE_NOTIFY_TEST_UNSET_KEY",
+		client:    server.Client(),
+	}
+
+	wavPath := filepath.Join(t.TempDir(), "out.wav")
+	err := backend.SynthesizeToFile(context.Background(), "hello", "bogus", SpeakOptions{}, wavPath)
+	if err == nil || !strings.Contains(err.Error(), "unknown voice") {
+		t.Errorf("SynthesizeToFile() error = %v, want server error detail", err)
+	}
+	if _, statErr := os.Stat(wavPath); !os.IsNotExist(statErr) {
+		t.Errorf("audio file should not be written after a failed request")
+	}
+}
@@ -33,7 +33,7 @@ func TestIntegration_ComponentCreation(t *testing.T) {
 	}
 
 	// Test server creation
-	server, err := CreateVoiceNotifyServer()
+	server, err := CreateVoiceNotifyServer(voiceSystem)
 	if err != nil {
 		t.Fatalf("Failed to create server: %v", err)
 	}
@@ -24,6 +24,11 @@ func (ld *LanguageDetector) IsAutoDetectEnabled() bool {
 	return ld.autoDetect
 }
 
+// DefaultLanguage returns the language used when none is detected
+func (ld *LanguageDetector) DefaultLanguage() string {
+	return ld.defaultLanguage
+}
+
 // DetectLanguage detects the language of the given text
 func (ld *LanguageDetector) DetectLanguage(text string) string {
 	if !ld.autoDetect {
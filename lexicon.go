@@ -0,0 +1,268 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// lexiconAllLanguages is the lexicon key for entries that apply to every language
+const lexiconAllLanguages = "*"
+
+// builtinLexicon holds pronunciations of common developer vocabulary.
+// Language-specific entries override the ones for all languages.
+var builtinLexicon = map[string]map[string]string{
+	lexiconAllLanguages: {
+		"kubectl":    "cube control",
+		"k8s":        "kubernetes",
+		"nginx":      "engine x",
+		"PostgreSQL": "postgres Q L",
+		"MySQL":      "my S Q L",
+		"SQLite":     "S Q lite",
+		"GitHub":     "git hub",
+		"GitLab":     "git lab",
+		"JSON":       "jason",
+		"YAML":       "yammel",
+		"OAuth":      "oh auth",
+		"macOS":      "mac O S",
+		"stdout":     "standard out",
+		"stderr":     "standard error",
+		"sudo":       "sue doo",
+		"CI":         "C I",
+		"CD":         "C D",
+		"PR":         "P R",
+		"PRs":        "P Rs",
+		"API":        "A P I",
+		"CLI":        "C L I",
+		"MCP":        "M C P",
+		"npm":        "N P M",
+		"TTS":        "T T S",
+	},
+	"ja": {
+		"kubectl":    "キューブコントロール",
+		"k8s":        "クバネティス",
+		"nginx":      "エンジンエックス",
+		"PostgreSQL": "ポストグレスキューエル",
+		"MySQL":      "マイエスキューエル",
+		"GitHub":     "ギットハブ",
+		"GitLab":     "ギットラボ",
+		"JSON":       "ジェイソン",
+		"YAML":       "ヤムル",
+		"CI":         "シーアイ",
+		"CD":         "シーディー",
+		"PR":         "ピーアール",
+		"API":        "エーピーアイ",
+		"CLI":        "シーエルアイ",
+		"npm":        "エヌピーエム",
+	},
+}
+
+// Lexicon rewrites terms that speech engines mispronounce, per language.
+// Terms without lowercase letters (acronyms such as "CI") match case-sensitively,
+// all other terms match in any case. Terms only match whole words.
+type Lexicon struct {
+	tables map[string]*lexiconTable
+}
+
+// lexiconTable holds the compiled substitutions for one language
+type lexiconTable struct {
+	entries     map[string]string // Effective entries as configured
+	sensitive   map[string]string // Case-sensitive terms
+	insensitive map[string]string // Case-insensitive terms, keyed in lower case
+	pattern     *regexp.Regexp
+}
+
+// NewLexicon creates the built-in lexicon extended by the file in VOICE_NOTIFY_LEXICON
+func NewLexicon() *Lexicon {
+	entries := builtinLexicon
+	if path := getEnv("VOICE_NOTIFY_LEXICON", ""); path != "" {
+		custom, err := loadLexiconFile(path)
+		if err != nil {
+			log.Printf("Failed to load VOICE_NOTIFY_LEXICON: %v", err)
+		} else {
+			entries = mergeLexicons(builtinLexicon, custom)
+			debugLog("Loaded pronunciation lexicon from %s", path)
+		}
+	}
+	return newLexicon(entries)
+}
+
+// newLexicon compiles the entries, keyed by language then term
+func newLexicon(entries map[string]map[string]string) *Lexicon {
+	l := &Lexicon{tables: make(map[string]*lexiconTable)}
+
+	for language := range entries {
+		merged := make(map[string]string)
+		for term, replacement := range entries[lexiconAllLanguages] {
+			merged[term] = replacement
+		}
+		for term, replacement := range entries[language] {
+			merged[term] = replacement
+		}
+		l.tables[language] = compileLexiconTable(merged)
+	}
+	if _, ok := l.tables[lexiconAllLanguages]; !ok {
+		l.tables[lexiconAllLanguages] = compileLexiconTable(nil)
+	}
+
+	return l
+}
+
+// loadLexiconFile reads a JSON lexicon file
+// Format: {"*": {"kubectl": "cube control"}, "ja": {"Grafana": "グラファナ"}}
+func loadLexiconFile(path string) (map[string]map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries map[string]map[string]string
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("invalid lexicon file %s: %w", path, err)
+	}
+	return entries, nil
+}
+
+// mergeLexicons overlays custom entries on the base lexicon.
+// An empty replacement removes the term, e.g. to disable a built-in entry.
+func mergeLexicons(base, custom map[string]map[string]string) map[string]map[string]string {
+	merged := make(map[string]map[string]string)
+	for _, source := range []map[string]map[string]string{base, custom} {
+		for language, terms := range source {
+			language = lexiconLanguage(language)
+			if merged[language] == nil {
+				merged[language] = make(map[string]string)
+			}
+			for term, replacement := range terms {
+				merged[language][strings.TrimSpace(term)] = strings.TrimSpace(replacement)
+			}
+		}
+	}
+	return merged
+}
+
+// compileLexiconTable builds a single regular expression matching every term, longest first
+func compileLexiconTable(entries map[string]string) *lexiconTable {
+	table := &lexiconTable{
+		entries:     make(map[string]string),
+		sensitive:   make(map[string]string),
+		insensitive: make(map[string]string),
+	}
+
+	terms := make([]string, 0, len(entries))
+	for term, replacement := range entries {
+		if term == "" || replacement == "" {
+			continue
+		}
+		table.entries[term] = replacement
+		terms = append(terms, term)
+	}
+	if len(terms) == 0 {
+		return table
+	}
+	sort.Slice(terms, func(i, j int) bool {
+		if len(terms[i]) != len(terms[j]) {
+			return len(terms[i]) > len(terms[j])
+		}
+		return terms[i] < terms[j]
+	})
+
+	alternatives := make([]string, 0, len(terms))
+	for _, term := range terms {
+		expr := regexp.QuoteMeta(term)
+		if isLexiconCaseSensitive(term) {
+			table.sensitive[term] = entries[term]
+		} else {
+			table.insensitive[strings.ToLower(term)] = entries[term]
+			expr = "(?i:" + expr + ")"
+		}
+
+		// \b only applies next to ASCII word characters, which also lets terms touch CJK text
+		if isASCIIWordChar(rune(term[0])) {
+			expr = `\b` + expr
+		}
+		if isASCIIWordChar(rune(term[len(term)-1])) {
+			expr += `\b`
+		}
+		alternatives = append(alternatives, expr)
+	}
+	table.pattern = regexp.MustCompile(strings.Join(alternatives, "|"))
+
+	return table
+}
+
+// isLexiconCaseSensitive reports whether a term is an acronym without lowercase letters
+func isLexiconCaseSensitive(term string) bool {
+	for _, r := range term {
+		if unicode.IsLower(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// isASCIIWordChar reports whether r is a word character for \b
+func isASCIIWordChar(r rune) bool {
+	return r == '_' || (r >= '0' && r <= '9') || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+}
+
+// lexiconLanguage reduces a language code or locale (e.g., "en-US") to the lexicon key
+func lexiconLanguage(language string) string {
+	if language == lexiconAllLanguages {
+		return language
+	}
+	return strings.Split(normalizeLocale(strings.TrimSpace(language)), "_")[0]
+}
+
+// table returns the substitutions for a language, falling back to the ones for all languages
+func (l *Lexicon) table(language string) *lexiconTable {
+	if table, ok := l.tables[lexiconLanguage(language)]; ok {
+		return table
+	}
+	return l.tables[lexiconAllLanguages]
+}
+
+// Apply replaces known terms in text with their pronunciation for the language.
+// SSML tags are left untouched.
+func (l *Lexicon) Apply(text, language string) string {
+	if l == nil {
+		return text
+	}
+	table := l.table(language)
+	if table.pattern == nil {
+		return text
+	}
+
+	replace := func(s string) string {
+		return table.pattern.ReplaceAllStringFunc(s, func(match string) string {
+			if replacement, ok := table.sensitive[match]; ok {
+				return replacement
+			}
+			if replacement, ok := table.insensitive[strings.ToLower(match)]; ok {
+				return replacement
+			}
+			return match
+		})
+	}
+
+	rewritten := rewriteOutsideMarkup(text, replace)
+	if rewritten != text {
+		debugLog("Lexicon rewrote %q to %q for language %q", text, rewritten, language)
+	}
+	return rewritten
+}
+
+// Entries returns the effective substitutions for every configured language.
+// The "*" entry applies to languages without their own table.
+func (l *Lexicon) Entries() map[string]map[string]string {
+	entries := make(map[string]map[string]string, len(l.tables))
+	for language, table := range l.tables {
+		entries[language] = table.entries
+	}
+	return entries
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// TestLexicon_Apply tests word-boundary, case-aware substitutions
+func TestLexicon_Apply(t *testing.T) {
+	lexicon := newLexicon(builtinLexicon)
+
+	tests := []struct {
+		name     string
+		text     string
+		language string
+		expected string
+	}{
+		{name: "term", text: "Run kubectl apply", language: "en", expected: "Run cube control apply"},
+		{name: "any_case", text: "postgresql is up", language: "en", expected: "postgres Q L is up"},
+		{name: "acronym", text: "CI passed, PR merged", language: "en", expected: "C I passed, P R merged"},
+		{name: "acronym_case_sensitive", text: "ci and pr", language: "en", expected: "ci and pr"},
+		{name: "word_boundary", text: "CIDR and SPRING", language: "en", expected: "CIDR and SPRING"},
+		{name: "other_language_uses_all", text: "nginx redémarré", language: "fr", expected: "engine x redémarré"},
+		{name: "language_override", text: "kubectlを実行しました", language: "ja", expected: "キューブコントロールを実行しました"},
+		{name: "locale", text: "CIが完了", language: "ja-JP", expected: "シーアイが完了"},
+		{name: "ssml_untouched", text: `<prosody rate="fast">PR</prosody> ready`, language: "en", expected: `<prosody rate="fast">P R</prosody> ready`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := lexicon.Apply(tt.text, tt.language); got != tt.expected {
+				t.Errorf("Apply(%q, %q) = %q, want %q", tt.text, tt.language, got, tt.expected)
+			}
+		})
+	}
+
+	var disabled *Lexicon
+	if got := disabled.Apply("kubectl", "en"); got != "kubectl" {
+		t.Errorf("nil lexicon Apply() = %q, want the text unchanged", got)
+	}
+}
+
+// TestNewLexicon_UserFile tests extending and overriding the built-in lexicon from a file
+func TestNewLexicon_UserFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lexicon.json")
+	content := `{"*": {"acme-api": "acme A P I", "sudo": ""}, "ja-JP": {"Grafana": "グラファナ"}}`
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("VOICE_NOTIFY_LEXICON", path)
+
+	lexicon := NewLexicon()
+	if got := lexicon.Apply("acme-api deployed with sudo", "en"); got != "acme A P I deployed with sudo" {
+		t.Errorf("Apply() = %q, want the custom term replaced and sudo left alone", got)
+	}
+	if got := lexicon.Apply("grafanaとkubectl", "ja"); got != "グラファナとキューブコントロール" {
+		t.Errorf("Apply() = %q, want custom and built-in Japanese entries", got)
+	}
+
+	t.Setenv("VOICE_NOTIFY_LEXICON", filepath.Join(t.TempDir(), "missing.json"))
+	if got := NewLexicon().Apply("kubectl", "en"); got != "cube control" {
+		t.Errorf("Apply() with a missing file = %q, want built-in entries", got)
+	}
+}
+
+// TestHandleLexiconResource tests exposing the lexicon as an MCP resource
+func TestHandleLexiconResource(t *testing.T) {
+	lexicon := newLexicon(map[string]map[string]string{
+		"*":  {"nginx": "engine x"},
+		"ja": {"CI": "シーアイ"},
+	})
+
+	request := mcp.ReadResourceRequest{}
+	request.Params.URI = "voice-notify://lexicon"
+	contents, err := handleLexiconResource(request, lexicon)
+	if err != nil || len(contents) != 1 {
+		t.Fatalf("handleLexiconResource() = %+v, %v", contents, err)
+	}
+
+	text, ok := contents[0].(mcp.TextResourceContents)
+	if !ok || text.MIMEType != "application/json" {
+		t.Fatalf("resource contents = %+v, want JSON text", contents[0])
+	}
+	var entries map[string]map[string]string
+	if err := json.Unmarshal([]byte(text.Text), &entries); err != nil {
+		t.Fatalf("resource is not valid JSON: %v", err)
+	}
+	if entries["ja"]["CI"] != "シーアイ" || entries["ja"]["nginx"] != "engine x" || entries["*"]["nginx"] != "engine x" {
+		t.Errorf("resource entries = %v, want merged per-language tables", entries)
+	}
+}
+
+// TestHandleNotifyVoice_Lexicon tests that the lexicon rewrites the spoken message
+func TestHandleNotifyVoice_Lexicon(t *testing.T) {
+	backend := &fakeBackend{}
+	vs := &VoiceSystem{backend: backend, availableVoices: map[string]VoiceInfo{}}
+	langDetect := &LanguageDetector{autoDetect: true, defaultLanguage: "en"}
+
+	result, err := handleNotifyVoice(context.Background(), newTestToolRequest(map[string]any{
+		"message": "PR merged",
+	}), vs, langDetect, &TextNormalizer{lexicon: newLexicon(builtinLexicon)}, newTestNotifier(), newSpeechJobTracker())
+	if err != nil || result.IsError {
+		t.Fatalf("handleNotifyVoice() = %+v, %v", result, err)
+	}
+	if len(backend.spoken) != 1 || backend.spoken[0].Message != "P R merged" {
+		t.Errorf("spoken = %+v, want the lexicon applied", backend.spoken)
+	}
+}
@@ -21,7 +21,8 @@ func main() {
 	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
 
 	// Create and configure the server
-	s, err := CreateVoiceNotifyServer()
+	voiceSystem := NewVoiceSystem()
+	s, err := CreateVoiceNotifyServer(voiceSystem)
 	if err != nil {
 		log.Fatalf("Failed to create server: %v", err)
 	}
@@ -33,6 +34,7 @@ func main() {
 		if err := server.ServeStdio(s); err != nil {
 			log.Printf("Server error: %v", err)
 			debugLog("Server error details: %+v", err)
+			voiceSystem.Close()
 			os.Exit(1)
 		}
 	}()
@@ -42,4 +44,7 @@ func main() {
 	log.Println("Shutdown signal received")
 	debugLog("Received signal, initiating graceful shutdown")
 	log.Println("Shutting down voice notify MCP server...")
+
+	// Kill any speech still playing; speech commands run in their own process group
+	voiceSystem.Close()
 }
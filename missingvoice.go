@@ -0,0 +1,220 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// Strategies for a message in a language no installed voice speaks
+const (
+	missingVoiceTransliterate = "transliterate" // Speak the message in Latin script with the fallback voice
+	missingVoicePhrase        = "phrase"        // Speak a canned phrase in the fallback language instead
+	missingVoiceText          = "text"          // Do not speak; write the message to the text sink
+	missingVoiceFail          = "fail"          // Return an error
+	missingVoiceNone          = "none"          // Speak the message with the fallback voice as it is
+)
+
+// errNoVoiceForLanguage is returned by the "fail" strategy
+var errNoVoiceForLanguage = errors.New("no installed voice speaks the language")
+
+// missingVoiceVocabulary holds the canned phrase announcing a message in another language
+type missingVoiceVocabulary struct {
+	Phrase  string            // Template for the phrase; %s is the language name
+	Generic string            // Phrase for languages without a name below
+	Names   map[string]string // Language names by language code
+}
+
+// missingVoiceVocabularies maps fallback languages to their canned phrases; other languages use English
+var missingVoiceVocabularies = map[string]missingVoiceVocabulary{
+	"en": {
+		Phrase: "New notification in %s", Generic: "New notification in another language",
+		Names: map[string]string{
+			"ru": "Russian", "uk": "Ukrainian", "ar": "Arabic", "he": "Hebrew", "zh": "Chinese", "ja": "Japanese",
+			"ko": "Korean", "el": "Greek", "hi": "Hindi", "th": "Thai", "en": "English", "fr": "French",
+			"de": "German", "es": "Spanish", "it": "Italian", "pt": "Portuguese",
+		},
+	},
+	"ja": {
+		Phrase: "%sの新しい通知があります", Generic: "他の言語の新しい通知があります",
+		Names: map[string]string{
+			"ru": "ロシア語", "uk": "ウクライナ語", "ar": "アラビア語", "he": "ヘブライ語", "zh": "中国語", "ja": "日本語",
+			"ko": "韓国語", "el": "ギリシャ語", "hi": "ヒンディー語", "th": "タイ語", "en": "英語", "fr": "フランス語",
+			"de": "ドイツ語", "es": "スペイン語", "it": "イタリア語", "pt": "ポルトガル語",
+		},
+	},
+	"fr": {
+		Phrase: "Nouvelle notification en %s", Generic: "Nouvelle notification dans une autre langue",
+		Names: map[string]string{
+			"ru": "russe", "uk": "ukrainien", "ar": "arabe", "he": "hébreu", "zh": "chinois", "ja": "japonais",
+			"ko": "coréen", "el": "grec", "hi": "hindi", "th": "thaï", "en": "anglais", "fr": "français",
+			"de": "allemand", "es": "espagnol", "it": "italien", "pt": "portugais",
+		},
+	},
+	"de": {
+		Phrase: "Neue Benachrichtigung auf %s", Generic: "Neue Benachrichtigung in einer anderen Sprache",
+		Names: map[string]string{
+			"ru": "Russisch", "uk": "Ukrainisch", "ar": "Arabisch", "he": "Hebräisch", "zh": "Chinesisch", "ja": "Japanisch",
+			"ko": "Koreanisch", "el": "Griechisch", "hi": "Hindi", "th": "Thailändisch", "en": "Englisch", "fr": "Französisch",
+			"de": "Deutsch", "es": "Spanisch", "it": "Italienisch", "pt": "Portugiesisch",
+		},
+	},
+	"es": {
+		Phrase: "Nueva notificación en %s", Generic: "Nueva notificación en otro idioma",
+		Names: map[string]string{
+			"ru": "ruso", "uk": "ucraniano", "ar": "árabe", "he": "hebreo", "zh": "chino", "ja": "japonés",
+			"ko": "coreano", "el": "griego", "hi": "hindi", "th": "tailandés", "en": "inglés", "fr": "francés",
+			"de": "alemán", "es": "español", "it": "italiano", "pt": "portugués",
+		},
+	},
+}
+
+// transliterations maps lowercase Cyrillic, Greek, Hebrew and Arabic letters to Latin script
+var transliterations = map[rune]string{
+	// Cyrillic (Russian and Ukrainian)
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "yo", 'ж': "zh", 'з': "z", 'и': "i",
+	'й': "y", 'к': "k", 'л': "l", 'м': "m", 'н': "n", 'о': "o", 'п': "p", 'р': "r", 'с': "s", 'т': "t",
+	'у': "u", 'ф': "f", 'х': "kh", 'ц': "ts", 'ч': "ch", 'ш': "sh", 'щ': "shch", 'ъ': "", 'ы': "y", 'ь': "",
+	'э': "e", 'ю': "yu", 'я': "ya", 'є': "ye", 'і': "i", 'ї': "yi", 'ґ': "g",
+	// Greek
+	'α': "a", 'β': "v", 'γ': "g", 'δ': "d", 'ε': "e", 'ζ': "z", 'η': "i", 'θ': "th", 'ι': "i", 'κ': "k",
+	'λ': "l", 'μ': "m", 'ν': "n", 'ξ': "x", 'ο': "o", 'π': "p", 'ρ': "r", 'σ': "s", 'ς': "s", 'τ': "t",
+	'υ': "y", 'φ': "f", 'χ': "ch", 'ψ': "ps", 'ω': "o", 'ά': "a", 'έ': "e", 'ή': "i", 'ί': "i", 'ό': "o",
+	'ύ': "y", 'ώ': "o", 'ϊ': "i", 'ϋ': "y", 'ΐ': "i", 'ΰ': "y",
+	// Hebrew
+	'א': "", 'ב': "b", 'ג': "g", 'ד': "d", 'ה': "h", 'ו': "v", 'ז': "z", 'ח': "ch", 'ט': "t", 'י': "y",
+	'כ': "k", 'ך': "kh", 'ל': "l", 'מ': "m", 'ם': "m", 'נ': "n", 'ן': "n", 'ס': "s", 'ע': "", 'פ': "p",
+	'ף': "f", 'צ': "ts", 'ץ': "ts", 'ק': "k", 'ר': "r", 'ש': "sh", 'ת': "t",
+	// Arabic
+	'ا': "a", 'أ': "a", 'إ': "i", 'آ': "aa", 'ب': "b", 'ت': "t", 'ث': "th", 'ج': "j", 'ح': "h", 'خ': "kh",
+	'د': "d", 'ذ': "dh", 'ر': "r", 'ز': "z", 'س': "s", 'ش': "sh", 'ص': "s", 'ض': "d", 'ط': "t", 'ظ': "z",
+	'ع': "", 'غ': "gh", 'ف': "f", 'ق': "q", 'ك': "k", 'ل': "l", 'م': "m", 'ن': "n", 'ه': "h", 'و': "w",
+	'ي': "y", 'ى': "a", 'ة': "a", 'ء': "", 'ئ': "", 'ؤ': "", '،': ",", '؛': ";", '؟': "?",
+}
+
+// missingVoiceOutcome is the result of applying a missing-voice strategy
+type missingVoiceOutcome struct {
+	Strategy string // Strategy applied, e.g. a phrase when the message's script cannot be transliterated
+	Language string // Language of Message, which the fallback voice speaks
+	Message  string // Text to speak; empty for the text strategy
+}
+
+// parseMissingVoiceStrategy validates a VOICE_NOTIFY_MISSING_VOICE value
+func parseMissingVoiceStrategy(value string) string {
+	strategy := strings.ToLower(strings.TrimSpace(value))
+	switch strategy {
+	case missingVoiceTransliterate, missingVoicePhrase, missingVoiceText, missingVoiceFail, missingVoiceNone:
+		return strategy
+	}
+	log.Printf("Invalid VOICE_NOTIFY_MISSING_VOICE %q, using %s", value, missingVoiceNone)
+	return missingVoiceNone
+}
+
+// applyMissingVoiceStrategy decides what to speak for a message in a language without a voice.
+// Transliteration falls back to the canned phrase for scripts it cannot convert, such as Chinese.
+func applyMissingVoiceStrategy(strategy, message, language, fallbackLanguage string) (missingVoiceOutcome, error) {
+	switch strategy {
+	case missingVoiceFail:
+		return missingVoiceOutcome{Strategy: strategy}, fmt.Errorf("%w: %s", errNoVoiceForLanguage, language)
+	case missingVoiceText:
+		return missingVoiceOutcome{Strategy: strategy, Language: language}, nil
+	case missingVoiceTransliterate:
+		if latin, ok := transliterate(message); ok {
+			return missingVoiceOutcome{Strategy: strategy, Language: fallbackLanguage, Message: latin}, nil
+		}
+		debugLog("Cannot transliterate the %s message, using the canned phrase", language)
+		fallthrough
+	case missingVoicePhrase:
+		return missingVoiceOutcome{Strategy: missingVoicePhrase, Language: fallbackLanguage, Message: missingVoicePhraseFor(language, fallbackLanguage)}, nil
+	default:
+		return missingVoiceOutcome{Strategy: missingVoiceNone, Language: language, Message: message}, nil
+	}
+}
+
+// missingVoicePhraseFor announces a message in language, in the fallback language,
+// e.g. "New notification in Russian"
+func missingVoicePhraseFor(language, fallbackLanguage string) string {
+	vocabulary, ok := missingVoiceVocabularies[lexiconLanguage(fallbackLanguage)]
+	if !ok {
+		vocabulary = missingVoiceVocabularies["en"]
+	}
+	if name, ok := vocabulary.Names[lexiconLanguage(language)]; ok {
+		return fmt.Sprintf(vocabulary.Phrase, name)
+	}
+	return vocabulary.Generic
+}
+
+// transliterate converts Cyrillic, Greek, Hebrew and Arabic text to Latin script, leaving SSML markup alone.
+// It reports false if letters of another script remain.
+func transliterate(text string) (string, bool) {
+	ok := true
+	result := rewriteOutsideMarkup(text, func(s string) string {
+		var latin strings.Builder
+		for _, r := range s {
+			lower := unicode.ToLower(r)
+			if replacement, found := transliterations[lower]; found {
+				if lower != r && replacement != "" {
+					// Capitalize the first letter only, so "Щ" reads as "Shch"
+					replacement = strings.ToUpper(replacement[:1]) + replacement[1:]
+				}
+				latin.WriteString(replacement)
+				continue
+			}
+			// Vowel points and other marks of the converted scripts are dropped
+			if unicode.Is(unicode.Mn, r) && unicode.In(r, unicode.Hebrew, unicode.Arabic) {
+				continue
+			}
+			if unicode.IsLetter(r) && !isLatin(r) {
+				ok = false
+			}
+			latin.WriteRune(r)
+		}
+		return latin.String()
+	})
+	return result, ok
+}
+
+// textSink receives notifications that are not spoken: appended to the file named by
+// VOICE_NOTIFY_TEXT_SINK, or written to stderr
+type textSink struct {
+	path   string
+	stderr io.Writer
+	mu     sync.Mutex
+}
+
+// newTextSinkFromEnv creates the text sink configured by VOICE_NOTIFY_TEXT_SINK
+func newTextSinkFromEnv() *textSink {
+	return &textSink{path: getEnv("VOICE_NOTIFY_TEXT_SINK", ""), stderr: os.Stderr}
+}
+
+// Write records the message as one line with its time and language
+func (s *textSink) Write(message, language string) error {
+	line := fmt.Sprintf("%s [%s] %s\n", time.Now().Format(time.RFC3339), language, strings.ReplaceAll(message, "\n", " "))
+	if s == nil {
+		_, err := io.WriteString(os.Stderr, line)
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.path == "" {
+		_, err := io.WriteString(s.stderr, line)
+		return err
+	}
+	file, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open text sink: %w", err)
+	}
+	if _, err := io.WriteString(file, line); err != nil {
+		_ = file.Close()
+		return fmt.Errorf("failed to write to text sink: %w", err)
+	}
+	return file.Close()
+}
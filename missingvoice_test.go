@@ -0,0 +1,190 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestTransliterate tests converting non-Latin scripts to Latin script
+func TestTransliterate(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected string
+		ok       bool
+	}{
+		{name: "russian", text: "Сборка завершена", expected: "Sborka zavershena", ok: true},
+		{name: "capital_digraph", text: "Щука", expected: "Shchuka", ok: true},
+		{name: "greek", text: "Καλημέρα", expected: "Kalimera", ok: true},
+		{name: "hebrew_points_dropped", text: "שָׁלוֹם", expected: "shlvm", ok: true},
+		{name: "arabic", text: "مرحبا؟", expected: "mrhba?", ok: true},
+		{name: "mixed_latin", text: "CI: тесты OK", expected: "CI: testy OK", ok: true},
+		{name: "ssml_untouched", text: `<break time="1s"/>Готово`, expected: `<break time="1s"/>Gotovo`, ok: true},
+		{name: "unsupported_script", text: "构建完成", expected: "构建完成", ok: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := transliterate(tt.text)
+			if got != tt.expected || ok != tt.ok {
+				t.Errorf("transliterate(%q) = %q, %v, want %q, %v", tt.text, got, ok, tt.expected, tt.ok)
+			}
+		})
+	}
+}
+
+// TestApplyMissingVoiceStrategy tests each strategy and the phrase fallback of transliteration
+func TestApplyMissingVoiceStrategy(t *testing.T) {
+	tests := []struct {
+		name     string
+		strategy string
+		message  string
+		language string
+		fallback string
+		expected missingVoiceOutcome
+	}{
+		{
+			name: "transliterate", strategy: missingVoiceTransliterate, message: "Готово", language: "ru", fallback: "en",
+			expected: missingVoiceOutcome{Strategy: missingVoiceTransliterate, Language: "en", Message: "Gotovo"},
+		},
+		{
+			name: "transliterate_unsupported", strategy: missingVoiceTransliterate, message: "完成", language: "zh", fallback: "en",
+			expected: missingVoiceOutcome{Strategy: missingVoicePhrase, Language: "en", Message: "New notification in Chinese"},
+		},
+		{
+			name: "phrase_localized", strategy: missingVoicePhrase, message: "Готово", language: "ru", fallback: "ja",
+			expected: missingVoiceOutcome{Strategy: missingVoicePhrase, Language: "ja", Message: "ロシア語の新しい通知があります"},
+		},
+		{
+			name: "phrase_unnamed_language", strategy: missingVoicePhrase, message: "Imeisha", language: "sw", fallback: "de",
+			expected: missingVoiceOutcome{Strategy: missingVoicePhrase, Language: "de", Message: "Neue Benachrichtigung in einer anderen Sprache"},
+		},
+		{
+			name: "text", strategy: missingVoiceText, message: "Готово", language: "ru", fallback: "en",
+			expected: missingVoiceOutcome{Strategy: missingVoiceText, Language: "ru"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := applyMissingVoiceStrategy(tt.strategy, tt.message, tt.language, tt.fallback)
+			if err != nil || got != tt.expected {
+				t.Errorf("applyMissingVoiceStrategy() = %+v, %v, want %+v", got, err, tt.expected)
+			}
+		})
+	}
+
+	if _, err := applyMissingVoiceStrategy(missingVoiceFail, "Готово", "ru", "en"); !errors.Is(err, errNoVoiceForLanguage) {
+		t.Errorf("fail strategy error = %v, want errNoVoiceForLanguage", err)
+	}
+}
+
+// TestVoiceSystem_ResolveMissingVoice tests when the strategy applies
+func TestVoiceSystem_ResolveMissingVoice(t *testing.T) {
+	vs := &VoiceSystem{
+		availableVoices: map[string]VoiceInfo{
+			"Samantha": {Name: "Samantha", Language: "en", Locale: "en_US"},
+			"Kyoko":    {Name: "Kyoko", Language: "ja", Locale: "ja_JP"},
+		},
+		missingVoice: missingVoicePhrase,
+	}
+
+	if outcome, err := vs.ResolveMissingVoice("完了", "ja", "en"); outcome != nil || err != nil {
+		t.Errorf("ResolveMissingVoice() with a Japanese voice = %+v, %v, want nil", outcome, err)
+	}
+	if outcome, err := vs.ResolveMissingVoice("Готово", "ru", "en"); err != nil || outcome == nil || outcome.Message != "New notification in Russian" {
+		t.Errorf("ResolveMissingVoice() = %+v, %v, want the English phrase", outcome, err)
+	}
+
+	// The phrase is in the language of the default voice
+	vs.defaultVoice = "Kyoko"
+	if outcome, _ := vs.ResolveMissingVoice("Готово", "ru", "en"); outcome == nil || outcome.Language != "ja" {
+		t.Errorf("ResolveMissingVoice() = %+v, want a Japanese phrase", outcome)
+	}
+
+	vs.missingVoice = missingVoiceNone
+	if outcome, err := vs.ResolveMissingVoice("Готово", "ru", "en"); outcome != nil || err != nil {
+		t.Errorf("ResolveMissingVoice() with strategy none = %+v, %v, want nil", outcome, err)
+	}
+
+	multilingual := &VoiceSystem{
+		availableVoices: map[string]VoiceInfo{"alloy": {Name: "alloy"}, "Samantha": {Name: "Samantha", Language: "en", Locale: "en_US"}},
+		missingVoice:    missingVoicePhrase,
+	}
+	if outcome, err := multilingual.ResolveMissingVoice("ビルドが完了しました", "ja", "en"); outcome != nil || err != nil {
+		t.Errorf("ResolveMissingVoice() with a voice of unknown language = %+v, %v, want nil", outcome, err)
+	}
+
+	empty := &VoiceSystem{availableVoices: map[string]VoiceInfo{}, missingVoice: missingVoiceFail}
+	if outcome, err := empty.ResolveMissingVoice("Готово", "ru", "en"); outcome != nil || err != nil {
+		t.Errorf("ResolveMissingVoice() without a catalog = %+v, %v, want nil", outcome, err)
+	}
+}
+
+// TestHandleNotifyVoice_MissingVoice tests the strategies through the tool handler
+func TestHandleNotifyVoice_MissingVoice(t *testing.T) {
+	voices := map[string]VoiceInfo{"Samantha": {Name: "Samantha", Language: "en", Locale: "en_US"}}
+	langDetect := &LanguageDetector{autoDetect: true, defaultLanguage: "en"}
+	request := newTestToolRequest(map[string]any{"message": "Сборка завершена"})
+
+	t.Run("transliterate", func(t *testing.T) {
+		backend := &fakeBackend{}
+		vs := &VoiceSystem{backend: backend, availableVoices: voices, missingVoice: missingVoiceTransliterate}
+		result, err := handleNotifyVoice(context.Background(), request, vs, langDetect, nil, newTestNotifier(), newSpeechJobTracker())
+		if err != nil || result.IsError {
+			t.Fatalf("handleNotifyVoice() = %+v, %v", result, err)
+		}
+		if len(backend.spoken) != 1 || backend.spoken[0].Message != "Sborka zavershena" || backend.spoken[0].Voice != "Samantha" {
+			t.Errorf("spoken = %+v, want the transliteration with Samantha", backend.spoken)
+		}
+		if text := resultText(t, result); !strings.Contains(text, "Missing voice strategy: transliterate") {
+			t.Errorf("response %q does not report the strategy", text)
+		}
+	})
+
+	t.Run("text", func(t *testing.T) {
+		backend := &fakeBackend{}
+		path := filepath.Join(t.TempDir(), "notifications.log")
+		vs := &VoiceSystem{backend: backend, availableVoices: voices, missingVoice: missingVoiceText, textSink: &textSink{path: path}}
+		result, err := handleNotifyVoice(context.Background(), request, vs, langDetect, nil, newTestNotifier(), newSpeechJobTracker())
+		if err != nil || result.IsError {
+			t.Fatalf("handleNotifyVoice() = %+v, %v", result, err)
+		}
+		if len(backend.spoken) != 0 {
+			t.Errorf("spoken = %+v, want nothing", backend.spoken)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil || !strings.Contains(string(data), "[ru] Сборка завершена") {
+			t.Errorf("text sink = %q, %v, want the message", data, err)
+		}
+		if text := resultText(t, result); !strings.Contains(text, "text sink") || !strings.Contains(text, "Missing voice strategy: text") {
+			t.Errorf("response %q does not report the text sink", text)
+		}
+	})
+
+	t.Run("fail", func(t *testing.T) {
+		backend := &fakeBackend{}
+		vs := &VoiceSystem{backend: backend, availableVoices: voices, missingVoice: missingVoiceFail}
+		result, err := handleNotifyVoice(context.Background(), request, vs, langDetect, nil, newTestNotifier(), newSpeechJobTracker())
+		if err != nil || !result.IsError {
+			t.Fatalf("handleNotifyVoice() = %+v, %v, want a tool error", result, err)
+		}
+		if len(backend.spoken) != 0 {
+			t.Errorf("spoken = %+v, want nothing", backend.spoken)
+		}
+	})
+
+	t.Run("installed_voice_requested", func(t *testing.T) {
+		backend := &fakeBackend{}
+		vs := &VoiceSystem{backend: backend, availableVoices: voices, missingVoice: missingVoiceFail}
+		request := newTestToolRequest(map[string]any{"message": "Сборка завершена", "voice": "Samantha"})
+		result, err := handleNotifyVoice(context.Background(), request, vs, langDetect, nil, newTestNotifier(), newSpeechJobTracker())
+		if err != nil || result.IsError || len(backend.spoken) != 1 {
+			t.Errorf("handleNotifyVoice() = %+v, %v, want the message spoken with the chosen voice", result, err)
+		}
+	})
+}
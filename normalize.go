@@ -0,0 +1,320 @@
+package main
+
+import (
+	"html"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// TextNormalizer turns agent messages into text that reads well aloud.
+// It runs before sanitization, so symbols are verbalized instead of being deleted.
+type TextNormalizer struct {
+	enabled    bool
+	lexicon    *Lexicon
+	verbalizer *CodeVerbalizer
+}
+
+// symbolVocabulary holds the words used to verbalize symbols in one language.
+// Templates contain %s where the number goes.
+type symbolVocabulary struct {
+	Percent     string // Template for "50%"
+	Number      string // Template for "#42"
+	And         string
+	To          string
+	Plus        string
+	Equals      string
+	At          string
+	Slash       string
+	About       string
+	Times       string
+	LessThan    string
+	GreaterThan string
+	Emoji       map[string]string // Emoji (without variation selectors) to words
+}
+
+// symbolVocabularies maps languages to their symbol words; other languages use English
+var symbolVocabularies = map[string]symbolVocabulary{
+	"en": {
+		Percent: "%s percent", Number: "number %s", And: "and", To: "to", Plus: "plus", Equals: "equals",
+		At: "at", Slash: "slash", About: "about", Times: "times", LessThan: "less than", GreaterThan: "greater than",
+		Emoji: map[string]string{
+			"✅": "done", "✔": "done", "✓": "done", "☑": "done",
+			"❌": "failed", "✗": "failed", "✖": "failed",
+			"⚠": "warning", "❗": "important", "❓": "question",
+			"🎉": "hooray", "🐛": "bug", "👍": "good", "💡": "idea", "📝": "note", "⏳": "waiting",
+		},
+	},
+	"ja": {
+		Percent: "%sパーセント", Number: "%s番", And: "アンド", To: "から", Plus: "プラス", Equals: "イコール",
+		At: "アット", Slash: "スラッシュ", About: "約", Times: "かける", LessThan: "未満", GreaterThan: "超",
+		Emoji: map[string]string{
+			"✅": "完了", "✔": "完了", "✓": "完了", "☑": "完了",
+			"❌": "失敗", "✗": "失敗", "✖": "失敗",
+			"⚠": "警告", "❗": "重要", "❓": "質問",
+			"🎉": "やった", "🐛": "バグ", "👍": "いいね", "💡": "アイデア", "📝": "メモ", "⏳": "待機中",
+		},
+	},
+	"fr": {
+		Percent: "%s pour cent", Number: "numéro %s", And: "et", To: "vers", Plus: "plus", Equals: "égal",
+		At: "arobase", Slash: "barre oblique", About: "environ", Times: "fois", LessThan: "inférieur à", GreaterThan: "supérieur à",
+		Emoji: map[string]string{
+			"✅": "terminé", "✔": "terminé", "✓": "terminé", "☑": "terminé",
+			"❌": "échec", "✗": "échec", "✖": "échec",
+			"⚠": "attention", "❗": "important", "❓": "question",
+			"🎉": "bravo", "🐛": "bogue", "👍": "bien", "💡": "idée", "📝": "note", "⏳": "en attente",
+		},
+	},
+	"de": {
+		Percent: "%s Prozent", Number: "Nummer %s", And: "und", To: "nach", Plus: "plus", Equals: "gleich",
+		At: "at", Slash: "Schrägstrich", About: "etwa", Times: "mal", LessThan: "kleiner als", GreaterThan: "größer als",
+		Emoji: map[string]string{
+			"✅": "erledigt", "✔": "erledigt", "✓": "erledigt", "☑": "erledigt",
+			"❌": "fehlgeschlagen", "✗": "fehlgeschlagen", "✖": "fehlgeschlagen",
+			"⚠": "Warnung", "❗": "wichtig", "❓": "Frage",
+			"🎉": "hurra", "🐛": "Fehler", "👍": "gut", "💡": "Idee", "📝": "Notiz", "⏳": "wartet",
+		},
+	},
+	"es": {
+		Percent: "%s por ciento", Number: "número %s", And: "y", To: "a", Plus: "más", Equals: "igual a",
+		At: "arroba", Slash: "barra", About: "aproximadamente", Times: "por", LessThan: "menor que", GreaterThan: "mayor que",
+		Emoji: map[string]string{
+			"✅": "hecho", "✔": "hecho", "✓": "hecho", "☑": "hecho",
+			"❌": "fallido", "✗": "fallido", "✖": "fallido",
+			"⚠": "advertencia", "❗": "importante", "❓": "pregunta",
+			"🎉": "bravo", "🐛": "error", "👍": "bien", "💡": "idea", "📝": "nota", "⏳": "esperando",
+		},
+	},
+}
+
+var (
+	// Markdown block syntax at the start of a line
+	markdownFencePattern   = regexp.MustCompile("^\\s*(```|~~~)")
+	markdownRulePattern    = regexp.MustCompile(`^\s*([-*_]\s*){3,}$`)
+	markdownHeadingPattern = regexp.MustCompile(`^\s*#{1,6}\s+`)
+	markdownQuotePattern   = regexp.MustCompile(`^\s*(>\s?)+`)
+	markdownListPattern    = regexp.MustCompile(`^\s*([-*+]|\d+[.)])\s+`)
+
+	// Markdown inline syntax
+	markdownAutolinkPattern = regexp.MustCompile(`<((?:https?://|www\.)[^<>\s]+)>`)
+	markdownImagePattern    = regexp.MustCompile(`!\[([^\]]*)\]\([^)]*\)`)
+	markdownLinkPattern     = regexp.MustCompile(`\[([^\]]+)\]\([^)]*\)`)
+	markdownCodePattern     = regexp.MustCompile("`+([^`]*)`+")
+	markdownStrongPattern   = regexp.MustCompile(`(?:\*\*|__)(\S(?:.*?\S)?)(?:\*\*|__)`)
+	markdownStrikePattern   = regexp.MustCompile(`~~(\S(?:.*?\S)?)~~`)
+	markdownEmPattern       = regexp.MustCompile(`[*_](\S(?:[^*_]*?\S)?)[*_]`)
+
+	// Symbols
+	entityPattern   = regexp.MustCompile(`&(?:#[0-9]+|#[xX][0-9A-Fa-f]+|[A-Za-z][A-Za-z0-9]*);|&`)
+	percentPattern  = regexp.MustCompile(`(\d+(?:[.,]\d+)?)\s*%`)
+	numberPattern   = regexp.MustCompile(`#(\d+)`)
+	hashtagPattern  = regexp.MustCompile(`(^|\s)#(\w)`)
+	arrowPattern    = regexp.MustCompile(`\s*(?:->|=>|→|⇒|⟶)\s*`)
+	aboutPattern    = regexp.MustCompile(`~\s*(\d)`)
+	spacePattern    = regexp.MustCompile(`\s+`)
+	sentenceEndings = ".!?:;,。！？、"
+)
+
+// NewTextNormalizer creates a normalizer configured by VOICE_NOTIFY_NORMALIZE
+// that also applies the pronunciation lexicon and the code verbalizer
+func NewTextNormalizer(lexicon *Lexicon, verbalizer *CodeVerbalizer) *TextNormalizer {
+	return &TextNormalizer{
+		enabled:    getEnvBool("VOICE_NOTIFY_NORMALIZE", true),
+		lexicon:    lexicon,
+		verbalizer: verbalizer,
+	}
+}
+
+// Normalize rewrites the message for speech in the given language.
+// Markdown is stripped first and code references, numbers and dates are read out, so the lexicon
+// sees plain words; identifiers are split and symbols and emoji verbalized last. SSML tags are left untouched.
+func (n *TextNormalizer) Normalize(text, language string) string {
+	if n == nil {
+		return text
+	}
+
+	if n.enabled {
+		// Plain text is spoken as is, so entities such as &amp; are decoded here rather than by the SSML parser
+		if !usesSSML(text) {
+			text = html.UnescapeString(text)
+		}
+		text = stripMarkdown(text)
+	}
+	text = n.verbalizer.VerbalizeReferences(text, language)
+	if n.enabled {
+		vocabulary := numberVocabularyFor(language)
+		text = rewriteOutsideMarkup(text, func(s string) string {
+			return verbalizeNumbers(s, vocabulary)
+		})
+	}
+	text = n.lexicon.Apply(text, language)
+	text = n.verbalizer.SplitIdentifiers(text, language)
+	if n.enabled {
+		vocabulary := symbolVocabularyFor(language)
+		text = rewriteOutsideMarkup(text, func(s string) string {
+			return verbalizeSymbols(s, vocabulary)
+		})
+	}
+
+	return strings.TrimSpace(text)
+}
+
+// symbolVocabularyFor returns the symbol words for a language, defaulting to English
+func symbolVocabularyFor(language string) symbolVocabulary {
+	if vocabulary, ok := symbolVocabularies[lexiconLanguage(language)]; ok {
+		return vocabulary
+	}
+	return symbolVocabularies["en"]
+}
+
+// rewriteOutsideMarkup applies rewrite to the text between SSML tags, leaving the tags untouched.
+// In SSML messages every tag is left alone, since the parser drops unsupported elements and keeps their text.
+func rewriteOutsideMarkup(text string, rewrite func(string) string) string {
+	tags := ssmlElementPattern
+	if ssmlTagPattern.MatchString(text) {
+		tags = markupTagPattern
+	}
+
+	var result strings.Builder
+	last := 0
+	for _, loc := range tags.FindAllStringIndex(text, -1) {
+		result.WriteString(rewrite(text[last:loc[0]]))
+		result.WriteString(text[loc[0]:loc[1]])
+		last = loc[1]
+	}
+	result.WriteString(rewrite(text[last:]))
+	return result.String()
+}
+
+// stripMarkdown removes markdown formatting and joins lines into sentences
+func stripMarkdown(text string) string {
+	var sentences []string
+	inCodeBlock := false
+	// A single line starting with "1." is a sentence, not a list
+	multiline := strings.Contains(strings.TrimSpace(text), "\n")
+	for _, line := range strings.Split(text, "\n") {
+		// Code blocks are not worth listening to
+		if markdownFencePattern.MatchString(line) {
+			inCodeBlock = !inCodeBlock
+			continue
+		}
+		if inCodeBlock || markdownRulePattern.MatchString(line) {
+			continue
+		}
+		line = markdownHeadingPattern.ReplaceAllString(line, "")
+		line = markdownQuotePattern.ReplaceAllString(line, "")
+		if multiline {
+			line = markdownListPattern.ReplaceAllString(line, "")
+		}
+		if line = strings.TrimSpace(line); line == "" {
+			continue
+		}
+
+		sentences = append(sentences, line)
+	}
+
+	// Lines become sentences so list items and headings do not run together
+	for i := 0; i < len(sentences)-1; i++ {
+		if !endsSentence(sentences[i]) {
+			sentences[i] += "."
+		}
+	}
+	text = strings.Join(sentences, " ")
+
+	text = markdownAutolinkPattern.ReplaceAllString(text, "$1")
+	text = markdownImagePattern.ReplaceAllString(text, "$1")
+	text = markdownLinkPattern.ReplaceAllString(text, "$1")
+	text = markdownCodePattern.ReplaceAllString(text, "$1")
+	text = stripEmphasis(text, markdownStrongPattern)
+	text = markdownStrikePattern.ReplaceAllString(text, "$1")
+	return stripEmphasis(text, markdownEmPattern)
+}
+
+// stripEmphasis removes emphasis markers matched by pattern around whole words,
+// leaving identifiers such as snake_case, __init__.py or a*b*c alone
+func stripEmphasis(text string, pattern *regexp.Regexp) string {
+	var result strings.Builder
+	last := 0
+	for _, loc := range pattern.FindAllStringSubmatchIndex(text, -1) {
+		before, _ := utf8.DecodeLastRuneInString(text[:loc[0]])
+		if (loc[0] > 0 && !unicode.IsSpace(before) && before != '(') || !emphasisEnds(text[loc[1]:]) {
+			continue
+		}
+		result.WriteString(text[last:loc[0]])
+		result.WriteString(text[loc[2]:loc[3]])
+		last = loc[1]
+	}
+	result.WriteString(text[last:])
+	return result.String()
+}
+
+// emphasisEnds reports whether closing emphasis markers followed by rest end a word:
+// rest is empty or starts with whitespace, or with punctuation that itself ends the word
+func emphasisEnds(rest string) bool {
+	after, size := utf8.DecodeRuneInString(rest)
+	switch {
+	case rest == "" || unicode.IsSpace(after):
+		return true
+	case strings.ContainsRune(").,!?:;", after):
+		next, _ := utf8.DecodeRuneInString(rest[size:])
+		return size == len(rest) || unicode.IsSpace(next) || strings.ContainsRune(").,!?:;", next)
+	}
+	return false
+}
+
+// endsSentence reports whether the line ends with punctuation
+func endsSentence(line string) bool {
+	runes := []rune(line)
+	return strings.ContainsRune(sentenceEndings, runes[len(runes)-1])
+}
+
+// verbalizeSymbols replaces symbols and common emoji with words and drops other emoji
+func verbalizeSymbols(text string, vocabulary symbolVocabulary) string {
+	// Emoji presentation selectors and joiners only change how the previous character is drawn
+	text = strings.NewReplacer("\uFE0F", "", "\uFE0E", "", "\u200D", "").Replace(text)
+	for emoji, word := range vocabulary.Emoji {
+		text = strings.ReplaceAll(text, emoji, " "+word+" ")
+	}
+	text = strings.Map(func(r rune) rune {
+		if isEmoji(r) {
+			return ' '
+		}
+		return r
+	}, text)
+
+	percentWord := strings.TrimSpace(strings.Replace(vocabulary.Percent, "%s", "", 1))
+	text = percentPattern.ReplaceAllString(text, strings.Replace(vocabulary.Percent, "%s", "${1}", 1))
+	text = numberPattern.ReplaceAllString(text, strings.Replace(vocabulary.Number, "%s", "${1}", 1))
+	text = hashtagPattern.ReplaceAllString(text, "$1$2")
+	text = arrowPattern.ReplaceAllString(text, " "+vocabulary.To+" ")
+	text = aboutPattern.ReplaceAllString(text, vocabulary.About+" $1")
+	// Entities such as &amp; in SSML text are left for the markup parser
+	text = entityPattern.ReplaceAllStringFunc(text, func(match string) string {
+		if match != "&" {
+			return match
+		}
+		return " " + vocabulary.And + " "
+	})
+	text = strings.NewReplacer(
+		"%", " "+percentWord+" ",
+		"+", " "+vocabulary.Plus+" ",
+		"=", " "+vocabulary.Equals+" ",
+		"@", " "+vocabulary.At+" ",
+		"/", " "+vocabulary.Slash+" ",
+		"×", " "+vocabulary.Times+" ",
+		"<", " "+vocabulary.LessThan+" ",
+		">", " "+vocabulary.GreaterThan+" ",
+		"…", "...",
+	).Replace(text)
+
+	return spacePattern.ReplaceAllString(text, " ")
+}
+
+// isEmoji reports whether r is in one of the emoji and pictograph blocks
+func isEmoji(r rune) bool {
+	return (r >= 0x1F000 && r <= 0x1FAFF) || // Mahjong tiles through Symbols and Pictographs Extended-A
+		(r >= 0x2600 && r <= 0x27BF) || // Miscellaneous Symbols and Dingbats
+		(r >= 0x2B00 && r <= 0x2BFF) || // Miscellaneous Symbols and Arrows
+		(r >= 0xE0020 && r <= 0xE007F) // Tag characters used in flag sequences
+}
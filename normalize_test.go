@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"testing"
+)
+
+// TestTextNormalizer_Normalize tests markdown stripping and symbol verbalization
+func TestTextNormalizer_Normalize(t *testing.T) {
+	normalizer := &TextNormalizer{enabled: true, lexicon: newLexicon(builtinLexicon)}
+
+	tests := []struct {
+		name     string
+		text     string
+		language string
+		expected string
+	}{
+		{name: "percent", text: "100% of tests passed", language: "en", expected: "100 percent of tests passed"},
+		{name: "percent_ja", text: "テストの100%が成功", language: "ja", expected: "テストの100パーセントが成功"},
+		{name: "percent_locale", text: "95,5 % couverts", language: "fr-FR", expected: "95,5 pour cent couverts"},
+		{name: "strong_and_code", text: "**Done**: updated `main.go`", language: "en", expected: "Done: updated main.go"},
+		{name: "link", text: "See [the PR](https://example.com/pr/1) for details", language: "en", expected: "See the P R for details"},
+		{name: "emphasis", text: "This is *really* _important_", language: "en", expected: "This is really important"},
+		{name: "snake_case_kept", text: "renamed max_retry_count", language: "en", expected: "renamed max_retry_count"},
+		{name: "lines", text: "## Summary\n- Build passed\n- Deploy done!\n\n```\nmake\n```", language: "en", expected: "Summary. Build passed. Deploy done!"},
+		{name: "emoji", text: "✅ Tests passed 🚀", language: "en", expected: "done Tests passed"},
+		{name: "emoji_variation", text: "⚠️ Disk almost full", language: "en", expected: "warning Disk almost full"},
+		{name: "emoji_ja", text: "❌ ビルド失敗", language: "ja", expected: "失敗 ビルド失敗"},
+		{name: "symbols", text: "Fix #42 & merge feature → main", language: "en", expected: "Fix number 42 and merge feature to main"},
+		{name: "comparison", text: "latency < 200ms", language: "en", expected: "latency less than two hundred milliseconds"},
+		{name: "about", text: "~5 minutes left", language: "en", expected: "about 5 minutes left"},
+		{name: "numbered_sentence", text: "1. tests failed", language: "en", expected: "1. tests failed"},
+		{name: "dunder_file", text: "Edit __init__.py now", language: "en", expected: "Edit __init__.py now"},
+		{name: "strong_sentence_end", text: "Build **failed**.", language: "en", expected: "Build failed."},
+		{name: "ssml_entity", text: "<speak>Tom &amp; Jerry</speak>", language: "en", expected: "<speak>Tom &amp; Jerry</speak>"},
+		{name: "plain_entity", text: "Tom &amp; Jerry", language: "en", expected: "Tom and Jerry"},
+		{name: "ssml_unsupported_tag", text: `<p>Hello</p><break/> world`, language: "en", expected: `<p>Hello</p><break/> world`},
+		{name: "ssml_sub", text: `<speak><sub alias="done">OK</sub> & more</speak>`, language: "en", expected: `<speak><sub alias="done">OK</sub> and more</speak>`},
+		{name: "ssml_untouched", text: `Done <break time="1s"/> 50% faster`, language: "en", expected: `Done <break time="1s"/> 50 percent faster`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := normalizer.Normalize(tt.text, tt.language); got != tt.expected {
+				t.Errorf("Normalize(%q, %q) = %q, want %q", tt.text, tt.language, got, tt.expected)
+			}
+		})
+	}
+}
+
+// TestTextNormalizer_Disabled tests that only the lexicon applies when normalization is off
+func TestTextNormalizer_Disabled(t *testing.T) {
+	t.Setenv("VOICE_NOTIFY_NORMALIZE", "false")
+	normalizer := NewTextNormalizer(newLexicon(builtinLexicon), nil)
+
+	if got := normalizer.Normalize("**PR** merged 100%", "en"); got != "**P R** merged 100%" {
+		t.Errorf("Normalize() = %q, want only the lexicon applied", got)
+	}
+
+	var none *TextNormalizer
+	if got := none.Normalize("**done**", "en"); got != "**done**" {
+		t.Errorf("nil normalizer Normalize() = %q, want the text unchanged", got)
+	}
+}
+
+// TestHandleNotifyVoice_Normalize tests that symbols survive sanitization as words
+func TestHandleNotifyVoice_Normalize(t *testing.T) {
+	backend := &fakeBackend{}
+	vs := &VoiceSystem{backend: backend, availableVoices: map[string]VoiceInfo{}}
+	langDetect := &LanguageDetector{autoDetect: true, defaultLanguage: "en"}
+	normalizer := &TextNormalizer{enabled: true}
+
+	result, err := handleNotifyVoice(context.Background(), newTestToolRequest(map[string]any{
+		"message": "**100%** of tests passed ✅",
+	}), vs, langDetect, normalizer, newTestNotifier(), newSpeechJobTracker())
+	if err != nil || result.IsError {
+		t.Fatalf("handleNotifyVoice() = %+v, %v", result, err)
+	}
+	if len(backend.spoken) != 1 || backend.spoken[0].Message != "100 percent of tests passed done" {
+		t.Errorf("spoken = %+v, want the normalized message", backend.spoken)
+	}
+}
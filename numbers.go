@@ -0,0 +1,299 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// numberVocabulary holds how one language reads numbers, quantities, versions and dates
+type numberVocabulary struct {
+	Words        func(number string) string // Reads a number such as "12" or "1.5"; nil speaks the digits
+	Decimal      string                     // Decimal separator when the digits are spoken
+	Separator    string                     // Between a number and its unit, and between duration parts
+	Units        map[string][2]string       // Unit symbol to singular and plural names
+	Version      string                     // Template for a version; %s is the version number
+	VersionPoint string                     // Between the parts of a version number
+	Fraction     string                     // Template for "42/50"; %[1]s is the numerator, %[2]s the denominator
+	Date         func(date time.Time) string
+}
+
+// numberVocabularies maps languages to how they read numbers; other languages use English
+var numberVocabularies = map[string]numberVocabulary{
+	"en": {
+		Words:     englishNumber,
+		Separator: " ",
+		Units: map[string][2]string{
+			"h": {"hour", "hours"}, "m": {"minute", "minutes"}, "s": {"second", "seconds"}, "ms": {"millisecond", "milliseconds"},
+			"KB": {"kilobyte", "kilobytes"}, "MB": {"megabyte", "megabytes"}, "GB": {"gigabyte", "gigabytes"}, "TB": {"terabyte", "terabytes"},
+		},
+		Version:      "version %s",
+		VersionPoint: " point ",
+		Fraction:     "%[1]s out of %[2]s",
+		Date: func(date time.Time) string {
+			return fmt.Sprintf("%s %s, %s", date.Month(), englishOrdinal(date.Day()), englishYear(date.Year()))
+		},
+	},
+	"ja": {
+		Decimal: ".",
+		Units: map[string][2]string{
+			"h": {"時間", "時間"}, "m": {"分", "分"}, "s": {"秒", "秒"}, "ms": {"ミリ秒", "ミリ秒"},
+			"KB": {"キロバイト", "キロバイト"}, "MB": {"メガバイト", "メガバイト"}, "GB": {"ギガバイト", "ギガバイト"}, "TB": {"テラバイト", "テラバイト"},
+		},
+		Version:      "バージョン%s",
+		VersionPoint: "点",
+		Fraction:     "%[2]s分の%[1]s",
+		Date: func(date time.Time) string {
+			return fmt.Sprintf("%d年%d月%d日", date.Year(), date.Month(), date.Day())
+		},
+	},
+	"fr": {
+		Decimal:   ",",
+		Separator: " ",
+		Units: map[string][2]string{
+			"h": {"heure", "heures"}, "m": {"minute", "minutes"}, "s": {"seconde", "secondes"}, "ms": {"milliseconde", "millisecondes"},
+			"KB": {"kilooctet", "kilooctets"}, "MB": {"mégaoctet", "mégaoctets"}, "GB": {"gigaoctet", "gigaoctets"}, "TB": {"téraoctet", "téraoctets"},
+		},
+		Version:      "version %s",
+		VersionPoint: " point ",
+		Fraction:     "%[1]s sur %[2]s",
+		Date: func(date time.Time) string {
+			months := []string{"janvier", "février", "mars", "avril", "mai", "juin", "juillet", "août", "septembre", "octobre", "novembre", "décembre"}
+			return fmt.Sprintf("%d %s %d", date.Day(), months[date.Month()-1], date.Year())
+		},
+	},
+	"de": {
+		Decimal:   ",",
+		Separator: " ",
+		Units: map[string][2]string{
+			"h": {"Stunde", "Stunden"}, "m": {"Minute", "Minuten"}, "s": {"Sekunde", "Sekunden"}, "ms": {"Millisekunde", "Millisekunden"},
+			"KB": {"Kilobyte", "Kilobyte"}, "MB": {"Megabyte", "Megabyte"}, "GB": {"Gigabyte", "Gigabyte"}, "TB": {"Terabyte", "Terabyte"},
+		},
+		Version:      "Version %s",
+		VersionPoint: " Punkt ",
+		Fraction:     "%[1]s von %[2]s",
+		Date: func(date time.Time) string {
+			months := []string{"Januar", "Februar", "März", "April", "Mai", "Juni", "Juli", "August", "September", "Oktober", "November", "Dezember"}
+			return fmt.Sprintf("%d. %s %d", date.Day(), months[date.Month()-1], date.Year())
+		},
+	},
+	"es": {
+		Decimal:   ",",
+		Separator: " ",
+		Units: map[string][2]string{
+			"h": {"hora", "horas"}, "m": {"minuto", "minutos"}, "s": {"segundo", "segundos"}, "ms": {"milisegundo", "milisegundos"},
+			"KB": {"kilobyte", "kilobytes"}, "MB": {"megabyte", "megabytes"}, "GB": {"gigabyte", "gigabytes"}, "TB": {"terabyte", "terabytes"},
+		},
+		Version:      "versión %s",
+		VersionPoint: " punto ",
+		Fraction:     "%[1]s de %[2]s",
+		Date: func(date time.Time) string {
+			months := []string{"enero", "febrero", "marzo", "abril", "mayo", "junio", "julio", "agosto", "septiembre", "octubre", "noviembre", "diciembre"}
+			return fmt.Sprintf("%d de %s de %d", date.Day(), months[date.Month()-1], date.Year())
+		},
+	},
+}
+
+var (
+	isoDatePattern      = regexp.MustCompile(`\d{4}-\d{2}-\d{2}`)
+	versionPattern      = regexp.MustCompile(`v\d+(?:\.\d+)+|\d+\.\d+\.\d+`)
+	durationPattern     = regexp.MustCompile(`(?:\d+(?:\.\d+)?(?:ms|h|m|s))+`)
+	durationPartPattern = regexp.MustCompile(`(\d+(?:\.\d+)?)(ms|h|m|s)`)
+	dataSizePattern     = regexp.MustCompile(`(\d+(?:\.\d+)?) ?(?:([KMGT])i?B|(k)B)`)
+	fractionPattern     = regexp.MustCompile(`(\d+)/(\d+)`)
+)
+
+var (
+	englishOnes = []string{"zero", "one", "two", "three", "four", "five", "six", "seven", "eight", "nine", "ten",
+		"eleven", "twelve", "thirteen", "fourteen", "fifteen", "sixteen", "seventeen", "eighteen", "nineteen"}
+	englishTens   = []string{"", "", "twenty", "thirty", "forty", "fifty", "sixty", "seventy", "eighty", "ninety"}
+	englishScales = []struct {
+		value int64
+		name  string
+	}{{1_000_000_000_000, "trillion"}, {1_000_000_000, "billion"}, {1_000_000, "million"}, {1000, "thousand"}, {100, "hundred"}}
+)
+
+// numberVocabularyFor returns how a language reads numbers, defaulting to English
+func numberVocabularyFor(language string) numberVocabulary {
+	if vocabulary, ok := numberVocabularies[lexiconLanguage(language)]; ok {
+		return vocabulary
+	}
+	return numberVocabularies["en"]
+}
+
+// verbalizeNumbers reads dates, versions, durations, data sizes and fractions in the vocabulary's language,
+// e.g. "3m12s" as "three minutes twelve seconds" in English or "3分12秒" in Japanese
+func verbalizeNumbers(text string, vocabulary numberVocabulary) string {
+	text = replaceStandalone(isoDatePattern, text, func(match []string) string {
+		date, err := time.Parse(time.DateOnly, match[0])
+		if err != nil {
+			return match[0]
+		}
+		return vocabulary.Date(date)
+	})
+	text = replaceStandalone(versionPattern, text, func(match []string) string {
+		parts := strings.Split(strings.TrimPrefix(match[0], "v"), ".")
+		for i, part := range parts {
+			parts[i] = vocabulary.number(part)
+		}
+		return fmt.Sprintf(vocabulary.Version, strings.Join(parts, vocabulary.VersionPoint))
+	})
+	text = replaceStandalone(durationPattern, text, func(match []string) string {
+		var parts []string
+		for _, part := range durationPartPattern.FindAllStringSubmatch(match[0], -1) {
+			parts = append(parts, vocabulary.quantity(part[1], part[2]))
+		}
+		return strings.Join(parts, vocabulary.Separator)
+	})
+	text = replaceStandalone(dataSizePattern, text, func(match []string) string {
+		return vocabulary.quantity(match[1], strings.ToUpper(match[2]+match[3])+"B")
+	})
+	return replaceStandalone(fractionPattern, text, func(match []string) string {
+		// Ratios such as 24/7 are not fractions
+		numerator, _ := strconv.Atoi(match[1])
+		denominator, _ := strconv.Atoi(match[2])
+		if denominator == 0 || numerator > denominator {
+			return match[0]
+		}
+		return fmt.Sprintf(vocabulary.Fraction, vocabulary.number(match[1]), vocabulary.number(match[2]))
+	})
+}
+
+// replaceStandalone replaces matches that are not part of a longer word, path or number,
+// so "3m12s" is read but "v3m12s" or "1.2.3.4" are left alone
+func replaceStandalone(pattern *regexp.Regexp, text string, replace func(match []string) string) string {
+	var result strings.Builder
+	last := 0
+	for _, loc := range pattern.FindAllStringSubmatchIndex(text, -1) {
+		if !isStandalone(text, loc[0], loc[1]) {
+			continue
+		}
+
+		match := make([]string, len(loc)/2)
+		for i := range match {
+			if loc[2*i] >= 0 {
+				match[i] = text[loc[2*i]:loc[2*i+1]]
+			}
+		}
+		result.WriteString(text[last:loc[0]])
+		result.WriteString(replace(match))
+		last = loc[1]
+	}
+	result.WriteString(text[last:])
+	return result.String()
+}
+
+// isStandalone reports whether text[start:end] is delimited by spaces, punctuation or non-ASCII text
+func isStandalone(text string, start, end int) bool {
+	if start > 0 {
+		before := rune(text[start-1])
+		if isASCIIWordChar(before) || before == '/' || before == '.' || before == '-' {
+			return false
+		}
+	}
+	if end < len(text) {
+		after := rune(text[end])
+		if isASCIIWordChar(after) || after == '/' {
+			return false
+		}
+		if (after == '.' || after == ',' || after == '-') && end+1 < len(text) && isASCIIWordChar(rune(text[end+1])) {
+			return false
+		}
+	}
+	return true
+}
+
+// number reads a number, as words or as digits with the language's decimal separator
+func (v numberVocabulary) number(number string) string {
+	if v.Words != nil {
+		return v.Words(number)
+	}
+	return strings.Replace(number, ".", v.Decimal, 1)
+}
+
+// quantity reads a number followed by the name of its unit
+func (v numberVocabulary) quantity(number, unit string) string {
+	names := v.Units[unit]
+	name := names[1]
+	if number == "1" {
+		name = names[0]
+	}
+	return v.number(number) + v.Separator + name
+}
+
+// englishNumber reads a number in English words, e.g. "1.25" as "one point two five"
+func englishNumber(number string) string {
+	integer, fraction, _ := strings.Cut(number, ".")
+	n, err := strconv.ParseInt(integer, 10, 64)
+	if err != nil || n >= 1000*englishScales[0].value {
+		return number
+	}
+
+	words := englishCardinal(n)
+	if fraction != "" {
+		words += " point"
+		for _, digit := range fraction {
+			words += " " + englishOnes[digit-'0']
+		}
+	}
+	return words
+}
+
+// englishCardinal reads a non-negative integer in English words
+func englishCardinal(n int64) string {
+	if n < 20 {
+		return englishOnes[n]
+	}
+	if n < 100 {
+		if n%10 == 0 {
+			return englishTens[n/10]
+		}
+		return englishTens[n/10] + "-" + englishOnes[n%10]
+	}
+
+	for _, scale := range englishScales {
+		if n >= scale.value {
+			words := englishCardinal(n/scale.value) + " " + scale.name
+			if rest := n % scale.value; rest > 0 {
+				words += " " + englishCardinal(rest)
+			}
+			return words
+		}
+	}
+	return strconv.FormatInt(n, 10)
+}
+
+// englishOrdinal reads a day of the month in English, e.g. 16 as "sixteenth"
+func englishOrdinal(n int) string {
+	words := englishCardinal(int64(n))
+	irregular := map[string]string{"one": "first", "two": "second", "three": "third", "five": "fifth", "eight": "eighth", "nine": "ninth", "twelve": "twelfth"}
+
+	cut := strings.LastIndexAny(words, " -") + 1
+	last := words[cut:]
+	switch {
+	case irregular[last] != "":
+		last = irregular[last]
+	case strings.HasSuffix(last, "y"):
+		last = strings.TrimSuffix(last, "y") + "ieth"
+	default:
+		last += "th"
+	}
+	return words[:cut] + last
+}
+
+// englishYear reads a year the way it is spoken, e.g. 2026 as "twenty twenty-six" and 2005 as "two thousand five"
+func englishYear(year int) string {
+	century, rest := year/100, year%100
+	switch {
+	case year < 1000 || (year >= 2000 && year < 2010):
+		return englishCardinal(int64(year))
+	case rest == 0:
+		return englishCardinal(int64(century)) + " hundred"
+	case rest < 10:
+		return englishCardinal(int64(century)) + " oh " + englishOnes[rest]
+	default:
+		return englishCardinal(int64(century)) + " " + englishCardinal(int64(rest))
+	}
+}
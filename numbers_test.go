@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"testing"
+)
+
+// TestVerbalizeNumbers tests reading durations, sizes, dates, versions and fractions per language
+func TestVerbalizeNumbers(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		language string
+		expected string
+	}{
+		{name: "duration", text: "Tests took 3m12s.", language: "en", expected: "Tests took three minutes twelve seconds."},
+		{name: "duration_ja", text: "テストは3m12sで完了", language: "ja", expected: "テストは3分12秒で完了"},
+		{name: "duration_singular", text: "1h1m in total", language: "en", expected: "one hour one minute in total"},
+		{name: "milliseconds", text: "p99 is 250ms", language: "en", expected: "p99 is two hundred fifty milliseconds"},
+		{name: "fractional_seconds", text: "1.5s", language: "de", expected: "1,5 Sekunden"},
+		{name: "data_size", text: "Image is 1.5GB", language: "en", expected: "Image is one point five gigabytes"},
+		{name: "data_size_fr", text: "Image de 1.5 GB", language: "fr", expected: "Image de 1,5 gigaoctets"},
+		{name: "data_size_binary", text: "512MiB free", language: "ja", expected: "512メガバイト free"},
+		{name: "date", text: "Released 2026-10-16", language: "en", expected: "Released October sixteenth, twenty twenty-six"},
+		{name: "date_ja", text: "2026-10-16にリリース", language: "ja", expected: "2026年10月16日にリリース"},
+		{name: "date_es", text: "Publicado 2026-10-01", language: "es", expected: "Publicado 1 de octubre de 2026"},
+		{name: "invalid_date", text: "2026-13-45", language: "en", expected: "2026-13-45"},
+		{name: "version", text: "Upgraded to v1.2.3", language: "en", expected: "Upgraded to version one point two point three"},
+		{name: "version_ja", text: "v1.2.3に更新", language: "ja", expected: "バージョン1点2点3に更新"},
+		{name: "bare_version", text: "go 1.22.5", language: "en", expected: "go version one point twenty-two point five"},
+		{name: "ip_address", text: "host 10.0.0.1", language: "en", expected: "host 10.0.0.1"},
+		{name: "fraction", text: "42/50 tests passed", language: "en", expected: "forty-two out of fifty tests passed"},
+		{name: "fraction_ja", text: "42/50件成功", language: "ja", expected: "50分の42件成功"},
+		{name: "ratio", text: "24/7 support", language: "en", expected: "24/7 support"},
+		{name: "half", text: "1/2 done", language: "en", expected: "one out of two done"},
+		{name: "api_path_version", text: "users in api v1", language: "en", expected: "users in api v1"},
+		{name: "slash_date", text: "10/16/2026", language: "en", expected: "10/16/2026"},
+		{name: "inside_word", text: "sha256sum and x3m12s", language: "en", expected: "sha256sum and x3m12s"},
+		{name: "unknown_language", text: "3m12s", language: "ko", expected: "three minutes twelve seconds"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := verbalizeNumbers(tt.text, numberVocabularyFor(tt.language)); got != tt.expected {
+				t.Errorf("verbalizeNumbers(%q, %q) = %q, want %q", tt.text, tt.language, got, tt.expected)
+			}
+		})
+	}
+}
+
+// TestEnglishNumbers tests English number, ordinal and year words
+func TestEnglishNumbers(t *testing.T) {
+	numbers := map[string]string{
+		"0":          "zero",
+		"15":         "fifteen",
+		"40":         "forty",
+		"101":        "one hundred one",
+		"2048":       "two thousand forty-eight",
+		"1000000":    "one million",
+		"3.14":       "three point one four",
+		"9999999999": "nine billion nine hundred ninety-nine million nine hundred ninety-nine thousand nine hundred ninety-nine",
+	}
+	for number, expected := range numbers {
+		if got := englishNumber(number); got != expected {
+			t.Errorf("englishNumber(%q) = %q, want %q", number, got, expected)
+		}
+	}
+
+	ordinals := map[int]string{1: "first", 2: "second", 3: "third", 12: "twelfth", 20: "twentieth", 21: "twenty-first", 30: "thirtieth", 31: "thirty-first"}
+	for day, expected := range ordinals {
+		if got := englishOrdinal(day); got != expected {
+			t.Errorf("englishOrdinal(%d) = %q, want %q", day, got, expected)
+		}
+	}
+
+	years := map[int]string{1999: "nineteen ninety-nine", 2000: "two thousand", 2005: "two thousand five", 2026: "twenty twenty-six", 1905: "nineteen oh five", 2100: "twenty-one hundred"}
+	for year, expected := range years {
+		if got := englishYear(year); got != expected {
+			t.Errorf("englishYear(%d) = %q, want %q", year, got, expected)
+		}
+	}
+}
+
+// TestHandleNotifyVoice_Numbers tests that numbers are read in the detected language
+func TestHandleNotifyVoice_Numbers(t *testing.T) {
+	backend := &fakeBackend{}
+	vs := &VoiceSystem{backend: backend, availableVoices: map[string]VoiceInfo{}}
+	langDetect := &LanguageDetector{autoDetect: true, defaultLanguage: "en"}
+	normalizer := &TextNormalizer{enabled: true, verbalizer: &CodeVerbalizer{}}
+
+	result, err := handleNotifyVoice(context.Background(), newTestToolRequest(map[string]any{
+		"message": "ビルドが3m12sで完了しました",
+	}), vs, langDetect, normalizer, newTestNotifier(), newSpeechJobTracker())
+	if err != nil || result.IsError {
+		t.Fatalf("handleNotifyVoice() = %+v, %v", result, err)
+	}
+	if len(backend.spoken) != 1 || backend.spoken[0].Message != "ビルドが3分12秒で完了しました" {
+		t.Errorf("spoken = %+v, want the duration read in Japanese", backend.spoken)
+	}
+}
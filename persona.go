@@ -0,0 +1,207 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+)
+
+// earconNone is the persona earcon that plays no earcon
+const earconNone = "none"
+
+// Persona bundles the voice, prosody and earcon of a kind of notification,
+// so the same sound identity is used without repeating parameters in every call
+type Persona struct {
+	Name   string            `json:"-"`
+	Voices map[string]string `json:"voices,omitempty"` // Voice by language or locale; "*" applies to every language
+	Rate   int               `json:"rate,omitempty"`   // Words per minute; 0 is the backend default
+	Pitch  int               `json:"pitch,omitempty"`  // 1-100, 50 is neutral; 0 is the backend default
+	Volume int               `json:"volume,omitempty"` // Percentage; 0 is the backend default
+	Earcon string            `json:"earcon,omitempty"` // Earcon name, "none" for silence; empty uses the priority's earcon
+}
+
+// builtinPersonas are the personas the priorities map to unless configured otherwise
+var builtinPersonas = map[string]Persona{
+	"urgent":  {Rate: 200}, // Faster speech
+	"neutral": {},          // Backend defaults
+	"calm":    {Rate: 150}, // Slower speech
+}
+
+// defaultPriorityPersonas maps notification priorities to personas
+var defaultPriorityPersonas = map[string]string{
+	"high":   "urgent",
+	"normal": "neutral",
+	"low":    "calm",
+}
+
+// PersonaSet holds the named personas and the persona used for each priority
+type PersonaSet struct {
+	personas   map[string]Persona
+	priorities map[string]string
+}
+
+// NewPersonaSet creates the built-in personas extended by the file in VOICE_NOTIFY_PERSONAS,
+// with priorities mapped by VOICE_NOTIFY_PRIORITY_PERSONAS. Settings outside the backend's
+// ranges are logged; they are clamped when spoken, like the notify_voice parameters.
+func NewPersonaSet(caps BackendCapabilities) *PersonaSet {
+	var custom map[string]Persona
+	if path := getEnv("VOICE_NOTIFY_PERSONAS", ""); path != "" {
+		var err error
+		if custom, err = loadPersonaFile(path); err != nil {
+			log.Printf("Failed to load VOICE_NOTIFY_PERSONAS: %v", err)
+		} else {
+			debugLog("Loaded %d personas from %s", len(custom), path)
+		}
+	}
+	ps := newPersonaSet(custom, parsePriorityPersonas(getEnv("VOICE_NOTIFY_PRIORITY_PERSONAS", "")))
+	for _, name := range ps.Names() {
+		_, adjustments := fitSpeakOptions(ps.personas[name].SpeakOptions(""), caps)
+		for _, adjustment := range adjustments {
+			if adjustment.Supported {
+				log.Printf("Persona %s: %s", name, adjustment)
+			}
+		}
+	}
+	return ps
+}
+
+// newPersonaSet merges custom personas over the built-in ones; a custom persona replaces
+// a built-in one of the same name. Priorities not in the mapping use defaultPriorityPersonas.
+func newPersonaSet(custom map[string]Persona, priorities map[string]string) *PersonaSet {
+	ps := &PersonaSet{personas: make(map[string]Persona), priorities: make(map[string]string)}
+	for _, source := range []map[string]Persona{builtinPersonas, custom} {
+		for name, persona := range source {
+			name = strings.ToLower(strings.TrimSpace(name))
+			persona.Name = name
+			ps.personas[name] = validatePersona(persona)
+		}
+	}
+
+	for priority, name := range defaultPriorityPersonas {
+		ps.priorities[priority] = name
+	}
+	for priority, name := range priorities {
+		if _, ok := ps.personas[name]; !ok {
+			log.Printf("Unknown persona %q for priority %s in VOICE_NOTIFY_PRIORITY_PERSONAS", name, priority)
+			continue
+		}
+		ps.priorities[priority] = name
+	}
+	return ps
+}
+
+// loadPersonaFile reads a JSON persona file
+// Format: {"ja-assistant": {"voices": {"ja": "Kyoko", "*": "Samantha"}, "rate": 180, "earcon": "success"}}
+func loadPersonaFile(path string) (map[string]Persona, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var personas map[string]Persona
+	if err := json.Unmarshal(data, &personas); err != nil {
+		return nil, fmt.Errorf("invalid persona file %s: %w", path, err)
+	}
+	return personas, nil
+}
+
+// parsePriorityPersonas parses a comma-separated priority mapping
+// Format: "high=urgent,low=calm"
+func parsePriorityPersonas(list string) map[string]string {
+	priorities := make(map[string]string)
+	for _, entry := range strings.Split(list, ",") {
+		priority, name, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		priority = strings.ToLower(strings.TrimSpace(priority))
+		name = strings.ToLower(strings.TrimSpace(name))
+		if !ok || priority == "" || name == "" {
+			if entry = strings.TrimSpace(entry); entry != "" {
+				log.Printf("Invalid VOICE_NOTIFY_PRIORITY_PERSONAS entry: %q", entry)
+			}
+			continue
+		}
+		priorities[priority] = name
+	}
+	return priorities
+}
+
+// validatePersona resets negative settings to the backend default. Ranges depend on the backend,
+// so other values are fitted with fitSpeakOptions when spoken.
+func validatePersona(persona Persona) Persona {
+	for setting, value := range map[string]*int{"rate": &persona.Rate, "pitch": &persona.Pitch, "volume": &persona.Volume} {
+		if *value < 0 {
+			log.Printf("Persona %s: invalid %s %d, using the default", persona.Name, setting, *value)
+			*value = 0
+		}
+	}
+	return persona
+}
+
+// Resolve returns the named persona, or the persona for the priority when name is empty.
+// A nil set resolves with the built-in personas.
+func (ps *PersonaSet) Resolve(name, priority string) (Persona, error) {
+	if ps == nil {
+		ps = newPersonaSet(nil, nil)
+	}
+
+	if name = strings.ToLower(strings.TrimSpace(name)); name != "" {
+		persona, ok := ps.personas[name]
+		if !ok {
+			return Persona{}, fmt.Errorf("unknown persona %q (available: %s)", name, strings.Join(ps.Names(), ", "))
+		}
+		return persona, nil
+	}
+	return ps.personas[ps.priorities[priority]], nil
+}
+
+// Names returns the persona names, sorted
+func (ps *PersonaSet) Names() []string {
+	if ps == nil {
+		ps = newPersonaSet(nil, nil)
+	}
+
+	names := make([]string, 0, len(ps.personas))
+	for name := range ps.personas {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Voice returns the persona's voice for a language, trying the locale, the language and then "*"
+func (p Persona) Voice(language string) string {
+	if len(p.Voices) == 0 {
+		return ""
+	}
+
+	voices := make(map[string]string, len(p.Voices))
+	for key, voice := range p.Voices {
+		voices[normalizeLocale(key)] = voice
+	}
+	locale := normalizeLocale(language)
+	base, _, _ := strings.Cut(locale, "_")
+	for _, key := range []string{locale, base, lexiconAllLanguages} {
+		if voice, ok := voices[key]; ok && key != "" {
+			return voice
+		}
+	}
+	return ""
+}
+
+// SpeakOptions returns the persona's prosody for a notification of the given priority
+func (p Persona) SpeakOptions(priority string) SpeakOptions {
+	return SpeakOptions{Rate: p.Rate, Pitch: p.Pitch, Volume: p.Volume, Priority: priority}
+}
+
+// EarconFor picks the earcon: a known status takes precedence, then the persona's earcon, then the priority's
+func (p Persona) EarconFor(priority, status string) string {
+	if _, ok := builtinEarcons[status]; ok || p.Earcon == "" {
+		return earconName(priority, status)
+	}
+	if p.Earcon == earconNone {
+		return ""
+	}
+	return p.Earcon
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestPersonaSet_Resolve tests named personas, priority defaults and overrides
+func TestPersonaSet_Resolve(t *testing.T) {
+	ps := newPersonaSet(map[string]Persona{
+		"Calm":         {Rate: 140, Volume: 70},
+		"ja-assistant": {Voices: map[string]string{"ja": "Kyoko"}, Rate: 180, Earcon: "question"},
+	}, map[string]string{"normal": "ja-assistant", "low": "missing"})
+
+	tests := []struct {
+		name     string
+		persona  string
+		priority string
+		expected SpeakOptions
+	}{
+		{name: "builtin_for_priority", priority: "high", expected: SpeakOptions{Rate: 200, Priority: "high"}},
+		{name: "custom_overrides_builtin", priority: "low", expected: SpeakOptions{Rate: 140, Volume: 70, Priority: "low"}},
+		{name: "priority_mapping", priority: "normal", expected: SpeakOptions{Rate: 180, Priority: "normal"}},
+		{name: "named_persona", persona: " URGENT ", priority: "low", expected: SpeakOptions{Rate: 200, Priority: "low"}},
+		{name: "unmapped_priority", priority: "critical", expected: SpeakOptions{Priority: "critical"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			persona, err := ps.Resolve(tt.persona, tt.priority)
+			if err != nil {
+				t.Fatalf("Resolve(%q, %q) unexpected error: %v", tt.persona, tt.priority, err)
+			}
+			if got := persona.SpeakOptions(tt.priority); got != tt.expected {
+				t.Errorf("Resolve(%q, %q) options = %+v, want %+v", tt.persona, tt.priority, got, tt.expected)
+			}
+		})
+	}
+
+	if _, err := ps.Resolve("chipper", "normal"); err == nil || !strings.Contains(err.Error(), "calm, ja-assistant, neutral, urgent") {
+		t.Errorf("Resolve(unknown) error = %v, want the available personas", err)
+	}
+
+	var builtin *PersonaSet
+	if persona, err := builtin.Resolve("", "low"); err != nil || persona.Name != "calm" {
+		t.Errorf("nil set Resolve() = %+v, %v, want the built-in calm persona", persona, err)
+	}
+}
+
+// TestPersona_Voice tests voice lookup by locale, language and wildcard
+func TestPersona_Voice(t *testing.T) {
+	persona := Persona{Voices: map[string]string{"en-GB": "Daniel", "en": "Samantha", "*": "Alex", "ja": "Kyoko"}}
+
+	tests := map[string]string{
+		"en_GB": "Daniel",
+		"en-US": "Samantha",
+		"ja":    "Kyoko",
+		"fr":    "Alex",
+		"":      "Alex",
+	}
+	for language, expected := range tests {
+		if got := persona.Voice(language); got != expected {
+			t.Errorf("Voice(%q) = %q, want %q", language, got, expected)
+		}
+	}
+	if got := (Persona{}).Voice("en"); got != "" {
+		t.Errorf("Voice() without voices = %q, want none", got)
+	}
+}
+
+// TestPersona_EarconFor tests that statuses win over the persona's earcon
+func TestPersona_EarconFor(t *testing.T) {
+	tests := []struct {
+		name     string
+		earcon   string
+		priority string
+		status   string
+		expected string
+	}{
+		{name: "priority_default", priority: "high", expected: "high"},
+		{name: "persona_earcon", earcon: "question", priority: "high", expected: "question"},
+		{name: "status_wins", earcon: "question", priority: "high", status: "failure", expected: "failure"},
+		{name: "silent", earcon: earconNone, priority: "normal", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := (Persona{Earcon: tt.earcon}).EarconFor(tt.priority, tt.status); got != tt.expected {
+				t.Errorf("EarconFor(%q, %q) = %q, want %q", tt.priority, tt.status, got, tt.expected)
+			}
+		})
+	}
+}
+
+// TestNewPersonaSet_Env tests loading personas and the priority mapping from the environment
+func TestNewPersonaSet_Env(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "personas.json")
+	content := `{"team": {"voices": {"*": "Samantha"}, "rate": 190, "pitch": -10, "volume": 150, "earcon": "success"}}`
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("VOICE_NOTIFY_PERSONAS", path)
+	t.Setenv("VOICE_NOTIFY_PRIORITY_PERSONAS", "normal=team, bad-entry")
+
+	espeak := (&espeakBackend{}).Capabilities()
+	persona, err := NewPersonaSet(espeak).Resolve("", "normal")
+	if err != nil {
+		t.Fatalf("Resolve() unexpected error: %v", err)
+	}
+	expected := Persona{Name: "team", Voices: map[string]string{"*": "Samantha"}, Rate: 190, Volume: 150, Earcon: "success"}
+	if !reflect.DeepEqual(persona, expected) {
+		t.Errorf("Resolve() = %+v, want %+v with the negative pitch dropped", persona, expected)
+	}
+
+	// The volume is fitted to each backend's range, as an agent-passed volume would be
+	if opts, _ := fitSpeakOptions(persona.SpeakOptions("normal"), espeak); opts.Volume != 150 {
+		t.Errorf("espeak-ng volume = %d, want 150", opts.Volume)
+	}
+	if opts, _ := fitSpeakOptions(persona.SpeakOptions("normal"), (&speechdBackend{}).Capabilities()); opts.Volume != 100 {
+		t.Errorf("speechd volume = %d, want 100", opts.Volume)
+	}
+}
+
+// TestHandleNotifyVoice_Persona tests that a persona sets the voice and prosody
+func TestHandleNotifyVoice_Persona(t *testing.T) {
+	backend := &fakeBackend{caps: BackendCapabilities{Rate: true, Volume: true}}
+	vs := &VoiceSystem{
+		backend: backend,
+		availableVoices: map[string]VoiceInfo{
+			"Kyoko": {Name: "Kyoko", Language: "ja", Locale: "ja_JP"},
+			"Otoya": {Name: "Otoya", Language: "ja", Locale: "ja_JP"},
+		},
+		personas: newPersonaSet(map[string]Persona{
+			"ja-assistant": {Voices: map[string]string{"ja": "Otoya"}, Rate: 180, Volume: 90},
+		}, nil),
+	}
+	langDetect := &LanguageDetector{autoDetect: true, defaultLanguage: "en"}
+
+	result, err := handleNotifyVoice(context.Background(), newTestToolRequest(map[string]any{
+		"message": "ビルドが完了しました",
+		"persona": "ja-assistant",
+	}), vs, langDetect, nil, newTestNotifier(), newSpeechJobTracker())
+	if err != nil || result.IsError {
+		t.Fatalf("handleNotifyVoice() = %+v, %v", result, err)
+	}
+
+	expected := fakeUtterance{Message: "ビルドが完了しました", Voice: "Otoya", Options: SpeakOptions{Rate: 180, Volume: 90, Priority: "normal"}}
+	if len(backend.spoken) != 1 || backend.spoken[0] != expected {
+		t.Errorf("spoken = %+v, want %+v", backend.spoken, expected)
+	}
+	if text := resultText(t, result); !strings.Contains(text, "Persona: ja-assistant") {
+		t.Errorf("response %q does not report the persona", text)
+	}
+
+	result, err = handleNotifyVoice(context.Background(), newTestToolRequest(map[string]any{
+		"message": "done",
+		"persona": "chipper",
+	}), vs, langDetect, nil, newTestNotifier(), newSpeechJobTracker())
+	if err != nil || !result.IsError {
+		t.Errorf("handleNotifyVoice() with an unknown persona = %+v, %v, want a tool error", result, err)
+	}
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// piperDefaultRate is the approximate speaking rate of piper at length_scale 1.0
+const piperDefaultRate = 175
+
+// piperBackend synthesizes speech with the piper neural TTS binary and local ONNX models
+type piperBackend struct {
+	command   string
+	modelsDir string
+
+	mu     sync.Mutex
+	models map[string]piperVoice
+}
+
+// piperVoice identifies a model file and, for multi-speaker models, a speaker
+type piperVoice struct {
+	ModelPath  string
+	SpeakerID  int
+	HasSpeaker bool
+}
+
+// piperModelConfig is the subset of a model's .onnx.json sidecar used for the voice catalog
+type piperModelConfig struct {
+	Language struct {
+		Code string `json:"code"`
+	} `json:"language"`
+	Espeak struct {
+		Voice string `json:"voice"`
+	} `json:"espeak"`
+	SpeakerIDMap map[string]int `json:"speaker_id_map"`
+}
+
+// newPiperBackend creates a piper backend from environment configuration
+func newPiperBackend() SpeechBackend {
+	return &piperBackend{
+		command:   getEnv("VOICE_NOTIFY_PIPER_COMMAND", "piper"),
+		modelsDir: getEnv("VOICE_NOTIFY_PIPER_MODELS", defaultPiperModelsDir()),
+	}
+}
+
+// defaultPiperModelsDir returns the conventional location for downloaded piper voices
+func defaultPiperModelsDir() string {
+	if dataHome := os.Getenv("XDG_DATA_HOME"); dataHome != "" {
+		return filepath.Join(dataHome, "piper", "voices")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "piper-voices"
+	}
+	return filepath.Join(home, ".local", "share", "piper", "voices")
+}
+
+// Name returns the backend identifier
+func (b *piperBackend) Name() string {
+	return "piper"
+}
+
+// Capabilities returns the features supported by piper
+func (b *piperBackend) Capabilities() BackendCapabilities {
+	return BackendCapabilities{Rate: true, RateRange: prosodyRange{Min: 50, Max: 400}}
+}
+
+// ListVoices scans the models directory and builds the catalog from the JSON sidecars
+func (b *piperBackend) ListVoices(ctx context.Context) ([]VoiceInfo, error) {
+	voices, models, err := scanPiperModels(b.modelsDir)
+	if err != nil {
+		return nil, err
+	}
+
+	b.mu.Lock()
+	b.models = models
+	b.mu.Unlock()
+
+	debugLog("Found %d piper voices in %s", len(voices), b.modelsDir)
+	return voices, nil
+}
+
+// SynthesizeToFile runs piper with the message on stdin and writes WAV audio to path
+func (b *piperBackend) SynthesizeToFile(ctx context.Context, message, voice string, opts SpeakOptions, path string) error {
+	model, err := b.resolveVoice(ctx, voice)
+	if err != nil {
+		return err
+	}
+
+	args := []string{"--model", model.ModelPath, "--output_file", path}
+	if model.HasSpeaker {
+		args = append(args, "--speaker", strconv.Itoa(model.SpeakerID))
+	}
+	if opts.Rate > 0 {
+		// length_scale > 1 is slower, < 1 is faster
+		lengthScale := float64(piperDefaultRate) / float64(opts.Rate)
+		args = append(args, "--length_scale", strconv.FormatFloat(lengthScale, 'f', 2, 64))
+	}
+
+	return runSpeechCommand(ctx, b.command, args, message)
+}
+
+// resolveVoice finds the model for a voice name, defaulting to the first installed model
+func (b *piperBackend) resolveVoice(ctx context.Context, voice string) (piperVoice, error) {
+	b.mu.Lock()
+	loaded := b.models != nil
+	b.mu.Unlock()
+
+	if !loaded {
+		if _, err := b.ListVoices(ctx); err != nil {
+			return piperVoice{}, err
+		}
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if voice != "" {
+		if model, ok := b.models[voice]; ok {
+			return model, nil
+		}
+		return piperVoice{}, fmt.Errorf("piper voice %q not found in %s", voice, b.modelsDir)
+	}
+
+	names := make([]string, 0, len(b.models))
+	for name := range b.models {
+		names = append(names, name)
+	}
+	if len(names) == 0 {
+		return piperVoice{}, fmt.Errorf("no piper voice models found in %s", b.modelsDir)
+	}
+	sort.Strings(names)
+	return b.models[names[0]], nil
+}
+
+// scanPiperModels reads every *.onnx model in dir along with its .onnx.json sidecar
+func scanPiperModels(dir string) ([]VoiceInfo, map[string]piperVoice, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.onnx"))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to scan piper models: %w", err)
+	}
+	sort.Strings(paths)
+
+	var voices []VoiceInfo
+	models := make(map[string]piperVoice)
+
+	for _, modelPath := range paths {
+		config, err := readPiperModelConfig(modelPath + ".json")
+		if err != nil {
+			debugLog("Skipping piper model %s: %v", modelPath, err)
+			continue
+		}
+
+		base := strings.TrimSuffix(filepath.Base(modelPath), ".onnx")
+		locale := piperLocale(base, config)
+		lang := strings.Split(locale, "_")[0]
+
+		if len(config.SpeakerIDMap) <= 1 {
+			voices = append(voices, VoiceInfo{Name: base, Language: lang, Locale: locale})
+			models[base] = piperVoice{ModelPath: modelPath}
+			continue
+		}
+
+		// Multi-speaker model: one voice per speaker, ordered by speaker ID
+		speakers := make([]string, 0, len(config.SpeakerIDMap))
+		for speaker := range config.SpeakerIDMap {
+			speakers = append(speakers, speaker)
+		}
+		sort.Slice(speakers, func(i, j int) bool {
+			return config.SpeakerIDMap[speakers[i]] < config.SpeakerIDMap[speakers[j]]
+		})
+		for _, speaker := range speakers {
+			name := base + ":" + speaker
+			voices = append(voices, VoiceInfo{Name: name, Language: lang, Locale: locale})
+			models[name] = piperVoice{
+				ModelPath:  modelPath,
+				SpeakerID:  config.SpeakerIDMap[speaker],
+				HasSpeaker: true,
+			}
+		}
+	}
+
+	return voices, models, nil
+}
+
+// readPiperModelConfig decodes a model's JSON sidecar
+func readPiperModelConfig(path string) (piperModelConfig, error) {
+	var config piperModelConfig
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return config, err
+	}
+	if err := json.Unmarshal(data, &config); err != nil {
+		return config, fmt.Errorf("invalid model config: %w", err)
+	}
+	return config, nil
+}
+
+// piperLocale determines a model's locale from its config, falling back to the file name
+// Example file name: "en_US-lessac-medium"
+func piperLocale(base string, config piperModelConfig) string {
+	if config.Language.Code != "" {
+		return config.Language.Code
+	}
+	if config.Espeak.Voice != "" {
+		return normalizeLocale(config.Espeak.Voice)
+	}
+	return strings.SplitN(base, "-", 2)[0]
+}
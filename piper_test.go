@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writePiperModel creates a fake .onnx model and its JSON sidecar
+func writePiperModel(t *testing.T, dir, base, config string) string {
+	t.Helper()
+
+	modelPath := filepath.Join(dir, base+".onnx")
+	if err := os.WriteFile(modelPath, []byte("onnx"), 0o644); err != nil {
+		t.Fatalf("failed to write model: %v", err)
+	}
+	if config != "" {
+		if err := os.WriteFile(modelPath+".json", []byte(config), 0o644); err != nil {
+			t.Fatalf("failed to write model config: %v", err)
+		}
+	}
+	return modelPath
+}
+
+// newTestPiperModels creates a models directory with single- and multi-speaker voices
+func newTestPiperModels(t *testing.T) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	writePiperModel(t, dir, "en_US-lessac-medium",
+		`{"language": {"code": "en_US", "family": "en"}, "num_speakers": 1, "speaker_id_map": {}}`)
+	writePiperModel(t, dir, "en_GB-vctk-medium",
+		`{"espeak": {"voice": "en-gb"}, "num_speakers": 2, "speaker_id_map": {"p239": 1, "p225": 0}}`)
+	writePiperModel(t, dir, "de_DE-thorsten-low", `{"speaker_id_map": {}}`)
+	writePiperModel(t, dir, "broken-model", `{not json`)
+	writePiperModel(t, dir, "missing-sidecar", "")
+	return dir
+}
+
+// TestScanPiperModels tests building the voice catalog from model sidecars
+func TestScanPiperModels(t *testing.T) {
+	dir := newTestPiperModels(t)
+
+	voices, models, err := scanPiperModels(dir)
+	if err != nil {
+		t.Fatalf("scanPiperModels() unexpected error: %v", err)
+	}
+
+	expected := []VoiceInfo{
+		{Name: "de_DE-thorsten-low", Language: "de", Locale: "de_DE"},
+		{Name: "en_GB-vctk-medium:p225", Language: "en", Locale: "en_GB"},
+		{Name: "en_GB-vctk-medium:p239", Language: "en", Locale: "en_GB"},
+		{Name: "en_US-lessac-medium", Language: "en", Locale: "en_US"},
+	}
+	if !reflect.DeepEqual(voices, expected) {
+		t.Errorf("scanPiperModels() voices = %+v, want %+v", voices, expected)
+	}
+
+	speaker := models["en_GB-vctk-medium:p239"]
+	if !speaker.HasSpeaker || speaker.SpeakerID != 1 {
+		t.Errorf("speaker p239 = %+v, want speaker ID 1", speaker)
+	}
+	if models["en_US-lessac-medium"].HasSpeaker {
+		t.Error("single-speaker model should not set a speaker")
+	}
+}
+
+// TestPiperBackend_SynthesizeToFile tests piper invocation using a stub piper command
+func TestPiperBackend_SynthesizeToFile(t *testing.T) {
+	dir := newTestPiperModels(t)
+	piperLog := installStubCommand(t, "piper", `
+while [ $# -gt 0 ]; do
+	if [ "$1" = "--output_file" ]; then out="$2"; fi
+	shift
+done
+cat > "$out"`)
+
+	backend := &piperBackend{command: "piper", modelsDir: dir}
+	wavPath := filepath.Join(t.TempDir(), "out.wav")
+	if err := backend.SynthesizeToFile(context.Background(), "Build done", "en_GB-vctk-medium:p239", SpeakOptions{Rate: 350}, wavPath); err != nil {
+		t.Fatalf("SynthesizeToFile() unexpected error: %v", err)
+	}
+
+	calls := readStubInvocations(t, piperLog)
+	if len(calls) != 1 {
+		t.Fatalf("expected 1 piper call, got %d", len(calls))
+	}
+	args := strings.Join(calls[0], " ")
+	for _, want := range []string{
+		"--model " + filepath.Join(dir, "en_GB-vctk-medium.onnx"),
+		"--output_file " + wavPath,
+		"--speaker 1",
+		"--length_scale 0.50",
+	} {
+		if !strings.Contains(args, want) {
+			t.Errorf("piper args %q missing %q", args, want)
+		}
+	}
+
+	// The stub writes its stdin, i.e. the message, as the "audio"
+	audio, err := os.ReadFile(wavPath)
+	if err != nil || string(audio) != "Build done" {
+		t.Errorf("audio file = %q (%v), want the message passed on stdin", audio, err)
+	}
+}
+
+// TestPiperBackend_ResolveVoice tests default and unknown voice handling
+func TestPiperBackend_ResolveVoice(t *testing.T) {
+	backend := &piperBackend{modelsDir: newTestPiperModels(t)}
+
+	model, err := backend.resolveVoice(context.Background(), "")
+	if err != nil {
+		t.Fatalf("resolveVoice(\"\") unexpected error: %v", err)
+	}
+	if filepath.Base(model.ModelPath) != "de_DE-thorsten-low.onnx" {
+		t.Errorf("default model = %s, want first model alphabetically", model.ModelPath)
+	}
+
+	if _, err := backend.resolveVoice(context.Background(), "Kyoko"); err == nil {
+		t.Error("resolveVoice(\"Kyoko\") expected error for unknown voice")
+	}
+
+	empty := &piperBackend{modelsDir: t.TempDir()}
+	if _, err := empty.resolveVoice(context.Background(), ""); err == nil {
+		t.Error("resolveVoice() expected error when no models are installed")
+	}
+}
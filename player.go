@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultPlayerTimeout bounds how long a single file may play
+const defaultPlayerTimeout = 60 * time.Second
+
+// AudioPlayer plays audio files produced by speech backends
+type AudioPlayer interface {
+	// Name returns the player identifier (e.g., "paplay")
+	Name() string
+	// Play plays the file and blocks until playback ends or ctx is done
+	Play(ctx context.Context, path string) error
+}
+
+// commandPlayer plays audio by running an external command with the file as last argument
+type commandPlayer struct {
+	name    string
+	command string
+	args    []string
+	timeout time.Duration
+}
+
+// knownAudioPlayers lists supported players in auto-detection order
+var knownAudioPlayers = []commandPlayer{
+	{name: "afplay", command: "afplay"},
+	{name: "pw-play", command: "pw-play"},
+	{name: "paplay", command: "paplay"},
+	{name: "aplay", command: "aplay", args: []string{"-q"}},
+	{name: "ffplay", command: "ffplay", args: []string{"-nodisp", "-autoexit", "-loglevel", "quiet"}},
+}
+
+// newAudioPlayer creates the configured player.
+// "auto" picks the first known player found on PATH, a known name selects that
+// player, and anything else is treated as a custom command line.
+func newAudioPlayer(config string, timeout time.Duration) (AudioPlayer, error) {
+	config = strings.TrimSpace(config)
+	if timeout <= 0 {
+		timeout = defaultPlayerTimeout
+	}
+
+	if config == "" || config == "auto" {
+		for _, player := range knownAudioPlayers {
+			if _, err := exec.LookPath(player.command); err == nil {
+				player.timeout = timeout
+				debugLog("Auto-detected audio player: %s", player.name)
+				return &player, nil
+			}
+		}
+		return nil, fmt.Errorf("no audio player found (tried %s)", strings.Join(audioPlayerNames(), ", "))
+	}
+
+	for _, player := range knownAudioPlayers {
+		if player.name == config {
+			player.timeout = timeout
+			return &player, nil
+		}
+	}
+
+	fields := strings.Fields(config)
+	return &commandPlayer{name: fields[0], command: fields[0], args: fields[1:], timeout: timeout}, nil
+}
+
+// newAudioPlayerFromEnv creates the player configured by VOICE_NOTIFY_PLAYER
+func newAudioPlayerFromEnv() (AudioPlayer, error) {
+	timeout := defaultPlayerTimeout
+	if seconds, err := strconv.Atoi(getEnv("VOICE_NOTIFY_PLAYER_TIMEOUT", "")); err == nil && seconds > 0 {
+		timeout = time.Duration(seconds) * time.Second
+	}
+	return newAudioPlayer(getEnv("VOICE_NOTIFY_PLAYER", "auto"), timeout)
+}
+
+// audioPlayerNames returns the names of the known players
+func audioPlayerNames() []string {
+	names := make([]string, 0, len(knownAudioPlayers))
+	for _, player := range knownAudioPlayers {
+		names = append(names, player.name)
+	}
+	return names
+}
+
+// Name returns the player identifier
+func (p *commandPlayer) Name() string {
+	return p.name
+}
+
+// Play runs the player command, killing it on timeout or when ctx is cancelled
+func (p *commandPlayer) Play(ctx context.Context, path string) error {
+	if _, hasDeadline := ctx.Deadline(); !hasDeadline && p.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.timeout)
+		defer cancel()
+	}
+
+	args := append(append([]string{}, p.args...), path)
+	err := runSpeechCommand(ctx, p.command, args, "")
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return fmt.Errorf("%s playback stopped: %w", p.name, ctxErr)
+	}
+	return err
+}
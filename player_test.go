@@ -0,0 +1,176 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// fakePlayer records played files for tests
+type fakePlayer struct {
+	played  []string
+	content []string
+	err     error
+}
+
+func (p *fakePlayer) Name() string {
+	return "fake"
+}
+
+func (p *fakePlayer) Play(ctx context.Context, path string) error {
+	data, _ := os.ReadFile(path)
+	p.played = append(p.played, path)
+	p.content = append(p.content, string(data))
+	return p.err
+}
+
+// fakeSynthBackend is a file-only backend that writes the message as the audio
+type fakeSynthBackend struct {
+	fakeBackend
+}
+
+func (f *fakeSynthBackend) SynthesizeToFile(ctx context.Context, message, voice string, opts SpeakOptions, path string) error {
+	f.spoken = append(f.spoken, fakeUtterance{Message: message, Voice: voice, Options: opts})
+	return os.WriteFile(path, []byte(message), 0o600)
+}
+
+// fileOnlyBackend hides fakeSynthBackend's Speak method so only synthesis is available
+type fileOnlyBackend struct {
+	synth *fakeSynthBackend
+}
+
+func (b fileOnlyBackend) Name() string                                        { return "file-only" }
+func (b fileOnlyBackend) ListVoices(ctx context.Context) ([]VoiceInfo, error) { return nil, nil }
+func (b fileOnlyBackend) Capabilities() BackendCapabilities                   { return BackendCapabilities{} }
+func (b fileOnlyBackend) SynthesizeToFile(ctx context.Context, message, voice string, opts SpeakOptions, path string) error {
+	return b.synth.SynthesizeToFile(ctx, message, voice, opts, path)
+}
+
+// TestNewAudioPlayer tests player selection from configuration
+func TestNewAudioPlayer(t *testing.T) {
+	tests := []struct {
+		config  string
+		name    string
+		command string
+		args    []string
+	}{
+		{config: "paplay", name: "paplay", command: "paplay"},
+		{config: "aplay", name: "aplay", command: "aplay", args: []string{"-q"}},
+		{config: "ffplay", name: "ffplay", command: "ffplay", args: []string{"-nodisp", "-autoexit", "-loglevel", "quiet"}},
+		{config: "mpv --no-video", name: "mpv", command: "mpv", args: []string{"--no-video"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.config, func(t *testing.T) {
+			player, err := newAudioPlayer(tt.config, time.Second)
+			if err != nil {
+				t.Fatalf("newAudioPlayer(%q) unexpected error: %v", tt.config, err)
+			}
+			cp := player.(*commandPlayer)
+			if cp.name != tt.name || cp.command != tt.command || !reflect.DeepEqual(cp.args, tt.args) {
+				t.Errorf("newAudioPlayer(%q) = %+v, want %s %s %v", tt.config, cp, tt.name, tt.command, tt.args)
+			}
+		})
+	}
+}
+
+// TestNewAudioPlayer_AutoDetect tests detection of players on PATH
+func TestNewAudioPlayer_AutoDetect(t *testing.T) {
+	// Restrict PATH to the stubs so real players on the machine are not detected
+	aplayDir := filepath.Dir(installStubCommand(t, "aplay", "exit 0"))
+	paplayDir := filepath.Dir(installStubCommand(t, "paplay", "exit 0"))
+	t.Setenv("PATH", aplayDir+string(os.PathListSeparator)+paplayDir)
+
+	player, err := newAudioPlayer("auto", 0)
+	if err != nil {
+		t.Fatalf("newAudioPlayer(auto) unexpected error: %v", err)
+	}
+	if player.Name() != "paplay" {
+		t.Errorf("auto-detected %s, want paplay (earlier in detection order)", player.Name())
+	}
+
+	t.Setenv("PATH", t.TempDir())
+	if _, err := newAudioPlayer("auto", 0); err == nil {
+		t.Error("newAudioPlayer(auto) expected error when no player is installed")
+	}
+}
+
+// TestCommandPlayer_Play tests playback arguments using a stub player
+func TestCommandPlayer_Play(t *testing.T) {
+	logPath := installStubCommand(t, "aplay", "exit 0")
+
+	player, _ := newAudioPlayer("aplay", time.Second)
+	if err := player.Play(context.Background(), "/tmp/voice.wav"); err != nil {
+		t.Fatalf("Play() unexpected error: %v", err)
+	}
+
+	expected := []string{"-q", "/tmp/voice.wav"}
+	if calls := readStubInvocations(t, logPath); len(calls) != 1 || !reflect.DeepEqual(calls[0], expected) {
+		t.Errorf("aplay invoked with %q, want %q", calls, expected)
+	}
+}
+
+// TestCommandPlayer_PlayTimeout tests that a hung player is killed after its timeout
+func TestCommandPlayer_PlayTimeout(t *testing.T) {
+	installStubCommand(t, "slowplay", "exec sleep 10")
+
+	player, _ := newAudioPlayer("slowplay", 100*time.Millisecond)
+	start := time.Now()
+	err := player.Play(context.Background(), "/tmp/voice.wav")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Play() error = %v, want deadline exceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Play() took %v, player was not killed", elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := player.Play(ctx, "/tmp/voice.wav"); !errors.Is(err, context.Canceled) {
+		t.Errorf("Play() with cancelled context error = %v, want context.Canceled", err)
+	}
+}
+
+// TestVoiceSystem_SpeakSynthesizeThenPlay tests that file-only backends are played through the player
+func TestVoiceSystem_SpeakSynthesizeThenPlay(t *testing.T) {
+	synth := &fakeSynthBackend{}
+	player := &fakePlayer{}
+	vs := &VoiceSystem{backend: fileOnlyBackend{synth: synth}, player: player}
+
+	if _, err := vs.Speak(context.Background(), "Build done", "en_US-lessac-medium", SpeakOptions{Priority: "high"}, ""); err != nil {
+		t.Fatalf("Speak() unexpected error: %v", err)
+	}
+
+	if len(synth.spoken) != 1 || synth.spoken[0].Voice != "en_US-lessac-medium" {
+		t.Fatalf("synthesized %+v, want one utterance with the selected voice", synth.spoken)
+	}
+	if len(player.played) != 1 || player.content[0] != "Build done" {
+		t.Fatalf("played %q, want the synthesized file", player.content)
+	}
+	if _, err := os.Stat(player.played[0]); !os.IsNotExist(err) {
+		t.Errorf("temporary audio file %s was not removed", player.played[0])
+	}
+
+	vs.player = nil
+	if _, err := vs.Speak(context.Background(), "Build done", "", SpeakOptions{Priority: "normal"}, ""); !errors.Is(err, errNoAudioPlayer) {
+		t.Errorf("Speak() without player error = %v, want %v", err, errNoAudioPlayer)
+	}
+}
+
+// TestVoiceSystem_SpeakPrefersDirect tests that backends able to speak directly skip the player
+func TestVoiceSystem_SpeakPrefersDirect(t *testing.T) {
+	backend := &fakeSynthBackend{}
+	player := &fakePlayer{}
+	vs := &VoiceSystem{backend: backend, player: player}
+
+	if _, err := vs.Speak(context.Background(), "hello", "", SpeakOptions{Priority: "normal"}, ""); err != nil {
+		t.Fatalf("Speak() unexpected error: %v", err)
+	}
+	if len(player.played) != 0 {
+		t.Errorf("player should not be used for a direct backend, played %q", player.played)
+	}
+}
@@ -0,0 +1,37 @@
+//go:build !windows
+
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// TestRunSpeechCommand_KillsProcessGroup tests that cancellation also kills helpers spawned by the command
+func TestRunSpeechCommand_KillsProcessGroup(t *testing.T) {
+	marker := filepath.Join(t.TempDir(), "helper-survived")
+	installStubCommand(t, "spawner", `(sleep 1; touch "`+marker+`") &
+exec sleep 10`)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err := runSpeechCommand(ctx, "spawner", nil, "")
+	if err == nil || !errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		t.Fatalf("runSpeechCommand() error = %v, want the command killed on timeout", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("runSpeechCommand() took %v, command was not killed", elapsed)
+	}
+
+	// The background helper would create the marker after one second if it were still alive
+	time.Sleep(1500 * time.Millisecond)
+	if _, err := os.Stat(marker); err == nil {
+		t.Error("helper process survived cancellation")
+	}
+}
@@ -0,0 +1,8 @@
+//go:build windows
+
+package main
+
+import "os/exec"
+
+// configureProcessGroup is a no-op on Windows; cancellation kills the process itself
+func configureProcessGroup(cmd *exec.Cmd) {}
@@ -0,0 +1,61 @@
+package main
+
+import "fmt"
+
+// prosodyRange is an inclusive range of SpeakOptions values a backend can honor
+type prosodyRange struct {
+	Min int
+	Max int
+}
+
+// Ranges assumed for backends that support a setting without declaring its range
+var (
+	defaultRateRange   = prosodyRange{Min: 80, Max: 450}
+	defaultPitchRange  = prosodyRange{Min: 1, Max: 100}
+	defaultVolumeRange = prosodyRange{Min: 1, Max: 100}
+)
+
+// prosodyAdjustment records a requested setting the backend cannot honor as given
+type prosodyAdjustment struct {
+	Setting   string // "rate", "pitch" or "volume"
+	Requested int
+	Applied   int // 0 when the backend does not support the setting
+	Range     prosodyRange
+	Supported bool
+}
+
+// String describes the adjustment for the tool result
+func (a prosodyAdjustment) String() string {
+	if !a.Supported {
+		return fmt.Sprintf("%s %d ignored: not supported by the backend", a.Setting, a.Requested)
+	}
+	return fmt.Sprintf("%s %d clamped to %d (supported range %d-%d)", a.Setting, a.Requested, a.Applied, a.Range.Min, a.Range.Max)
+}
+
+// fitSpeakOptions clamps the options to the backend's ranges and drops settings it does not support.
+// Zero values mean the backend default and are left alone.
+func fitSpeakOptions(opts SpeakOptions, caps BackendCapabilities) (SpeakOptions, []prosodyAdjustment) {
+	var adjustments []prosodyAdjustment
+	fit := func(setting string, value *int, supported bool, limits, fallback prosodyRange) {
+		if *value == 0 {
+			return
+		}
+		if limits == (prosodyRange{}) {
+			limits = fallback
+		}
+		if !supported {
+			adjustments = append(adjustments, prosodyAdjustment{Setting: setting, Requested: *value, Range: limits})
+			*value = 0
+			return
+		}
+		if clamped := max(limits.Min, min(limits.Max, *value)); clamped != *value {
+			adjustments = append(adjustments, prosodyAdjustment{Setting: setting, Requested: *value, Applied: clamped, Range: limits, Supported: true})
+			*value = clamped
+		}
+	}
+
+	fit("rate", &opts.Rate, caps.Rate, caps.RateRange, defaultRateRange)
+	fit("pitch", &opts.Pitch, caps.Pitch, caps.PitchRange, defaultPitchRange)
+	fit("volume", &opts.Volume, caps.Volume, caps.VolumeRange, defaultVolumeRange)
+	return opts, adjustments
+}
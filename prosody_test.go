@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// TestFitSpeakOptions tests clamping to declared ranges and dropping unsupported settings
+func TestFitSpeakOptions(t *testing.T) {
+	tests := []struct {
+		name        string
+		opts        SpeakOptions
+		caps        BackendCapabilities
+		expected    SpeakOptions
+		adjustments []prosodyAdjustment
+	}{
+		{
+			name:     "in_range",
+			opts:     SpeakOptions{Rate: 200, Pitch: 60, Volume: 80, Priority: "high"},
+			caps:     BackendCapabilities{Rate: true, Pitch: true, Volume: true, RateRange: prosodyRange{Min: 90, Max: 500}},
+			expected: SpeakOptions{Rate: 200, Pitch: 60, Volume: 80, Priority: "high"},
+		},
+		{
+			name:     "clamped_to_declared_range",
+			opts:     SpeakOptions{Rate: 900, Volume: 150},
+			caps:     BackendCapabilities{Rate: true, Volume: true, RateRange: prosodyRange{Min: 90, Max: 500}},
+			expected: SpeakOptions{Rate: 500, Volume: 100},
+			adjustments: []prosodyAdjustment{
+				{Setting: "rate", Requested: 900, Applied: 500, Range: prosodyRange{Min: 90, Max: 500}, Supported: true},
+				{Setting: "volume", Requested: 150, Applied: 100, Range: defaultVolumeRange, Supported: true},
+			},
+		},
+		{
+			name:     "negative_raised_to_minimum",
+			opts:     SpeakOptions{Pitch: -20},
+			caps:     BackendCapabilities{Pitch: true},
+			expected: SpeakOptions{Pitch: 1},
+			adjustments: []prosodyAdjustment{
+				{Setting: "pitch", Requested: -20, Applied: 1, Range: defaultPitchRange, Supported: true},
+			},
+		},
+		{
+			name:     "unsupported",
+			opts:     SpeakOptions{Rate: 180, Pitch: 70},
+			caps:     BackendCapabilities{Rate: true, RateRange: prosodyRange{Min: 50, Max: 400}},
+			expected: SpeakOptions{Rate: 180},
+			adjustments: []prosodyAdjustment{
+				{Setting: "pitch", Requested: 70, Range: defaultPitchRange},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, adjustments := fitSpeakOptions(tt.opts, tt.caps)
+			if got != tt.expected || !reflect.DeepEqual(adjustments, tt.adjustments) {
+				t.Errorf("fitSpeakOptions(%+v) = %+v, %+v, want %+v, %+v", tt.opts, got, adjustments, tt.expected, tt.adjustments)
+			}
+		})
+	}
+}
+
+// TestHandleNotifyVoice_Prosody tests the rate, pitch and volume parameters and the clamping report
+func TestHandleNotifyVoice_Prosody(t *testing.T) {
+	backend := &fakeBackend{name: "say", caps: BackendCapabilities{
+		Rate: true, Pitch: true, Volume: true, RateRange: prosodyRange{Min: 90, Max: 500},
+	}}
+	vs := &VoiceSystem{backend: backend, availableVoices: map[string]VoiceInfo{}}
+	langDetect := &LanguageDetector{autoDetect: true, defaultLanguage: "en"}
+
+	result, err := handleNotifyVoice(context.Background(), newTestToolRequest(map[string]any{
+		"message":  "Build finished",
+		"priority": "high",
+		"rate":     float64(900),
+		"volume":   float64(40),
+	}), vs, langDetect, nil, newTestNotifier(), newSpeechJobTracker())
+	if err != nil || result.IsError {
+		t.Fatalf("handleNotifyVoice() = %+v, %v", result, err)
+	}
+
+	// The urgent persona's rate is overridden and clamped
+	expected := SpeakOptions{Rate: 500, Volume: 40, Priority: "high"}
+	if len(backend.spoken) != 1 || backend.spoken[0].Options != expected {
+		t.Errorf("spoken = %+v, want options %+v", backend.spoken, expected)
+	}
+	if text := resultText(t, result); !strings.Contains(text, "Prosody: rate 900 clamped to 500 (supported range 90-500)") {
+		t.Errorf("response %q does not report the clamping", text)
+	}
+	warning, _ := mcp.AsTextContent(result.Content[len(result.Content)-1])
+	if len(result.Content) != 2 || warning == nil || !strings.Contains(warning.Text, "prosody_clamped") {
+		t.Errorf("result content = %+v, want a prosody_clamped warning", result.Content)
+	}
+
+	// Persona values the backend cannot apply are dropped without a report
+	rateOnly := &fakeBackend{name: "piper", caps: BackendCapabilities{Rate: true, RateRange: prosodyRange{Min: 50, Max: 400}}}
+	vs.backend = rateOnly
+	vs.personas = newPersonaSet(map[string]Persona{"soft": {Rate: 160, Volume: 60}}, nil)
+	result, err = handleNotifyVoice(context.Background(), newTestToolRequest(map[string]any{
+		"message": "Build finished",
+		"persona": "soft",
+	}), vs, langDetect, nil, newTestNotifier(), newSpeechJobTracker())
+	if err != nil || result.IsError {
+		t.Fatalf("handleNotifyVoice() = %+v, %v", result, err)
+	}
+	if len(rateOnly.spoken) != 1 || rateOnly.spoken[0].Options != (SpeakOptions{Rate: 160, Priority: "normal"}) {
+		t.Errorf("spoken = %+v, want the volume dropped", rateOnly.spoken)
+	}
+	if text := resultText(t, result); len(result.Content) != 1 || strings.Contains(text, "Prosody:") {
+		t.Errorf("result = %+v, want no prosody report for persona values", result.Content)
+	}
+}
@@ -0,0 +1,257 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+// defaultSpeechRate is the speaking rate (words per minute) assumed when a backend uses its default
+const defaultSpeechRate = 175
+
+var (
+	// errSpeechCancelled is returned for jobs that were stopped, flushed, or cancelled
+	errSpeechCancelled = errors.New("speech cancelled")
+	// errQueueClosed is returned for jobs enqueued after the queue was closed
+	errQueueClosed = errors.New("speech queue closed")
+)
+
+// SpeechJob is a notification waiting in or taken from the speech queue
+type SpeechJob struct {
+	ID        string
+	Message   string
+	Voice     string
+	Options   SpeakOptions // Prosody and priority of the message
+	Earcon    string       // Earcon played before the message, empty for none
+	Estimated time.Duration
+	Backend   string // Backend that delivered the message, set once the job has finished
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+	err    error
+}
+
+// Wait blocks until the job has finished and returns its error.
+// If ctx is done first, ctx's error is returned and the job keeps running.
+func (j *SpeechJob) Wait(ctx context.Context) error {
+	select {
+	case <-j.done:
+		return j.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// finish records the job result and releases waiters
+func (j *SpeechJob) finish(err error) {
+	j.cancel()
+	j.err = err
+	close(j.done)
+}
+
+// speechQueue serializes playback so concurrent notifications never overlap
+type speechQueue struct {
+	speak   func(ctx context.Context, message, voice string, opts SpeakOptions, earcon string) (string, error)
+	ctx     context.Context
+	stop    context.CancelFunc
+	mu      sync.Mutex
+	pending []*SpeechJob
+	active  *SpeechJob
+	nextID  uint64
+	closed  bool
+	wake    chan struct{}
+	exited  chan struct{}
+}
+
+// newSpeechQueue creates a queue and starts its worker
+func newSpeechQueue(speak func(ctx context.Context, message, voice string, opts SpeakOptions, earcon string) (string, error)) *speechQueue {
+	ctx, stop := context.WithCancel(context.Background())
+	q := &speechQueue{
+		speak:  speak,
+		ctx:    ctx,
+		stop:   stop,
+		wake:   make(chan struct{}, 1),
+		exited: make(chan struct{}),
+	}
+	go q.run()
+	return q
+}
+
+// Enqueue adds a notification to the queue and returns the job with the
+// number of notifications ahead of it (0 means it is spoken immediately)
+func (q *speechQueue) Enqueue(message, voice string, opts SpeakOptions, earcon string, estimated time.Duration) (*SpeechJob, int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.nextID++
+	job := &SpeechJob{
+		ID:        fmt.Sprintf("job-%d", q.nextID),
+		Message:   message,
+		Voice:     voice,
+		Options:   opts,
+		Earcon:    earcon,
+		Estimated: estimated,
+		done:      make(chan struct{}),
+	}
+	job.ctx, job.cancel = context.WithCancel(q.ctx)
+
+	if q.closed {
+		job.finish(errQueueClosed)
+		return job, 0
+	}
+
+	position := len(q.pending)
+	if q.active != nil {
+		position++
+	}
+	q.pending = append(q.pending, job)
+	debugLog("Queued speech job %s at position %d", job.ID, position)
+
+	// Wake the worker without blocking if it is already awake
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+	return job, position
+}
+
+// Len returns the number of queued and speaking jobs
+func (q *speechQueue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.active != nil {
+		return len(q.pending) + 1
+	}
+	return len(q.pending)
+}
+
+// Cancel removes a queued job or stops it if it is being spoken.
+// It reports whether the job was still pending or active.
+func (q *speechQueue) Cancel(job *SpeechJob) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.active == job {
+		debugLog("Stopping speech job %s", job.ID)
+		job.cancel()
+		return true
+	}
+	for i, pending := range q.pending {
+		if pending == job {
+			debugLog("Removing queued speech job %s", job.ID)
+			q.pending = append(q.pending[:i], q.pending[i+1:]...)
+			job.finish(errSpeechCancelled)
+			return true
+		}
+	}
+	return false
+}
+
+// Stop stops the job being spoken and, if flush is set, drops all queued jobs.
+// It reports whether a job was stopped and how many were flushed.
+func (q *speechQueue) Stop(flush bool) (bool, int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	stopped := q.active != nil
+	if stopped {
+		q.active.cancel()
+	}
+
+	var flushed int
+	if flush {
+		flushed = q.flushLocked()
+	}
+	debugLog("Stopped speech queue - Active: %v, Flushed: %d", stopped, flushed)
+	return stopped, flushed
+}
+
+// Close stops the active job, drops queued jobs, and waits for the worker to exit
+func (q *speechQueue) Close() {
+	q.mu.Lock()
+	if !q.closed {
+		q.closed = true
+		q.stop()
+		q.flushLocked()
+		close(q.wake)
+	}
+	q.mu.Unlock()
+
+	<-q.exited
+}
+
+// flushLocked cancels all pending jobs. q.mu must be held.
+func (q *speechQueue) flushLocked() int {
+	flushed := len(q.pending)
+	for _, job := range q.pending {
+		job.finish(errSpeechCancelled)
+	}
+	q.pending = nil
+	return flushed
+}
+
+// run speaks queued jobs one at a time
+func (q *speechQueue) run() {
+	defer close(q.exited)
+
+	for range q.wake {
+		for job := q.next(); job != nil; job = q.next() {
+			backend, err := q.speak(job.ctx, job.Message, job.Voice, job.Options, job.Earcon)
+			job.Backend = backend
+			if job.ctx.Err() != nil {
+				// Errors from a killed backend are expected when a job is stopped
+				err = errSpeechCancelled
+			} else if err != nil {
+				log.Printf("Speech job %s failed: %v", job.ID, err)
+			}
+			job.finish(err)
+		}
+	}
+}
+
+// next marks the previous job finished and takes the head of the queue
+func (q *speechQueue) next() *SpeechJob {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.active = nil
+	if len(q.pending) == 0 {
+		return nil
+	}
+	q.active = q.pending[0]
+	q.pending = q.pending[1:]
+	return q.active
+}
+
+// estimateSpeechDuration estimates how long a message takes to speak at rate words per minute.
+// CJK characters are counted as half a word since they are not separated by spaces.
+func estimateSpeechDuration(message string, rate int) time.Duration {
+	if rate <= 0 {
+		rate = defaultSpeechRate
+	}
+
+	var words float64
+	for _, field := range strings.Fields(message) {
+		var cjk, other int
+		for _, r := range field {
+			if unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul) {
+				cjk++
+			} else if unicode.IsLetter(r) || unicode.IsDigit(r) {
+				other++
+			}
+		}
+		words += float64(cjk) / 2
+		if other > 0 {
+			words++
+		}
+	}
+
+	return time.Duration(words / float64(rate) * float64(time.Minute)).Round(100 * time.Millisecond)
+}
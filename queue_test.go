@@ -0,0 +1,202 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestSpeechQueue_Serializes tests that jobs are spoken one at a time in order
+func TestSpeechQueue_Serializes(t *testing.T) {
+	release := make(chan struct{})
+	var (
+		mu        sync.Mutex
+		spoken    []string
+		active    int
+		maxActive int
+	)
+	q := newSpeechQueue(func(ctx context.Context, message, voice string, opts SpeakOptions, earcon string) (string, error) {
+		mu.Lock()
+		active++
+		maxActive = max(maxActive, active)
+		mu.Unlock()
+
+		<-release
+
+		mu.Lock()
+		active--
+		spoken = append(spoken, message)
+		mu.Unlock()
+		return "fake", nil
+	})
+
+	first, position := q.Enqueue("first", "", SpeakOptions{Priority: "normal"}, "", 0)
+	if position != 0 {
+		t.Errorf("first job position = %d, want 0", position)
+	}
+
+	// Wait for the worker to pick up the first job so positions are deterministic
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		mu.Lock()
+		started := active == 1
+		mu.Unlock()
+		if started || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	second, position := q.Enqueue("second", "", SpeakOptions{Priority: "normal"}, "", 0)
+	if position != 1 {
+		t.Errorf("second job position = %d, want 1", position)
+	}
+	third, position := q.Enqueue("third", "", SpeakOptions{Priority: "normal"}, "", 0)
+	if position != 2 {
+		t.Errorf("third job position = %d, want 2", position)
+	}
+	if first.ID == second.ID || second.ID == third.ID {
+		t.Errorf("job IDs are not unique: %s, %s, %s", first.ID, second.ID, third.ID)
+	}
+	if q.Len() != 3 {
+		t.Errorf("Len() = %d, want 3", q.Len())
+	}
+
+	close(release)
+	for _, job := range []*SpeechJob{first, second, third} {
+		if err := job.Wait(context.Background()); err != nil {
+			t.Errorf("job %s unexpected error: %v", job.ID, err)
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if maxActive != 1 {
+		t.Errorf("%d jobs spoke concurrently, want 1", maxActive)
+	}
+	if len(spoken) != 3 || spoken[0] != "first" || spoken[1] != "second" || spoken[2] != "third" {
+		t.Errorf("spoken = %q, want first, second, third", spoken)
+	}
+}
+
+// TestSpeechQueue_Error tests that speak errors are reported to waiters
+func TestSpeechQueue_Error(t *testing.T) {
+	speakErr := errors.New("say failed")
+	q := newSpeechQueue(func(ctx context.Context, message, voice string, opts SpeakOptions, earcon string) (string, error) {
+		return "", speakErr
+	})
+
+	job, _ := q.Enqueue("hello", "", SpeakOptions{Priority: "normal"}, "", 0)
+	if err := job.Wait(context.Background()); !errors.Is(err, speakErr) {
+		t.Errorf("Wait() error = %v, want %v", err, speakErr)
+	}
+}
+
+// TestEstimateSpeechDuration tests speaking time estimates
+func TestEstimateSpeechDuration(t *testing.T) {
+	tests := []struct {
+		name     string
+		message  string
+		rate     int
+		expected time.Duration
+	}{
+		{name: "default rate", message: "Build completed successfully", rate: 0, expected: 1 * time.Second},
+		{name: "fast rate", message: "one two three four five", rate: 300, expected: 1 * time.Second},
+		{name: "punctuation only", message: "- !", rate: 175, expected: 0},
+		{name: "japanese", message: "ビルドが完了しました", rate: 150, expected: 2 * time.Second},
+		{name: "empty", message: "", rate: 175, expected: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := estimateSpeechDuration(tt.message, tt.rate); got != tt.expected {
+				t.Errorf("estimateSpeechDuration(%q, %d) = %v, want %v", tt.message, tt.rate, got, tt.expected)
+			}
+		})
+	}
+}
+
+// blockingSpeak speaks until ctx is cancelled, recording each message as it starts
+func blockingSpeak(started chan<- string) func(ctx context.Context, message, voice string, opts SpeakOptions, earcon string) (string, error) {
+	return func(ctx context.Context, message, voice string, opts SpeakOptions, earcon string) (string, error) {
+		started <- message
+		<-ctx.Done()
+		return "", ctx.Err()
+	}
+}
+
+// TestSpeechQueue_Stop tests stopping the active job and flushing queued ones
+func TestSpeechQueue_Stop(t *testing.T) {
+	started := make(chan string, 10)
+	q := newSpeechQueue(blockingSpeak(started))
+	defer q.Close()
+
+	first, _ := q.Enqueue("first", "", SpeakOptions{Priority: "normal"}, "", 0)
+	second, _ := q.Enqueue("second", "", SpeakOptions{Priority: "normal"}, "", 0)
+	third, _ := q.Enqueue("third", "", SpeakOptions{Priority: "normal"}, "", 0)
+	fourth, _ := q.Enqueue("fourth", "", SpeakOptions{Priority: "normal"}, "", 0)
+
+	if message := <-started; message != "first" {
+		t.Fatalf("started %q, want first", message)
+	}
+	if stopped, flushed := q.Stop(false); !stopped || flushed != 0 {
+		t.Errorf("Stop(false) = %v, %d, want true, 0", stopped, flushed)
+	}
+	if err := first.Wait(context.Background()); !errors.Is(err, errSpeechCancelled) {
+		t.Errorf("stopped job error = %v, want %v", err, errSpeechCancelled)
+	}
+
+	// Cancelling a queued job removes it without speaking it
+	if message := <-started; message != "second" {
+		t.Fatalf("started %q, want second", message)
+	}
+	if !q.Cancel(third) {
+		t.Error("Cancel() of a queued job returned false")
+	}
+	if err := third.Wait(context.Background()); !errors.Is(err, errSpeechCancelled) {
+		t.Errorf("cancelled job error = %v, want %v", err, errSpeechCancelled)
+	}
+
+	if stopped, flushed := q.Stop(true); !stopped || flushed != 1 {
+		t.Errorf("Stop(true) = %v, %d, want true, 1", stopped, flushed)
+	}
+	for _, job := range []*SpeechJob{second, fourth} {
+		if err := job.Wait(context.Background()); !errors.Is(err, errSpeechCancelled) {
+			t.Errorf("job %s error = %v, want %v", job.ID, err, errSpeechCancelled)
+		}
+	}
+	if q.Cancel(fourth) {
+		t.Error("Cancel() of a finished job returned true")
+	}
+
+	select {
+	case message := <-started:
+		t.Errorf("flushed job %q was spoken", message)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+// TestSpeechQueue_Close tests that closing stops speech and rejects new jobs
+func TestSpeechQueue_Close(t *testing.T) {
+	started := make(chan string, 10)
+	q := newSpeechQueue(blockingSpeak(started))
+
+	active, _ := q.Enqueue("active", "", SpeakOptions{Priority: "normal"}, "", 0)
+	queued, _ := q.Enqueue("queued", "", SpeakOptions{Priority: "normal"}, "", 0)
+	<-started
+
+	q.Close()
+	for _, job := range []*SpeechJob{active, queued} {
+		if err := job.Wait(context.Background()); !errors.Is(err, errSpeechCancelled) {
+			t.Errorf("job %s error = %v, want %v", job.ID, err, errSpeechCancelled)
+		}
+	}
+
+	late, _ := q.Enqueue("late", "", SpeakOptions{Priority: "normal"}, "", 0)
+	if err := late.Wait(context.Background()); !errors.Is(err, errQueueClosed) {
+		t.Errorf("job enqueued after Close error = %v, want %v", err, errQueueClosed)
+	}
+	q.Close() // Closing twice is safe
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"log"
+	"os"
+	"sort"
+	"strings"
+)
+
+// voiceQualityRanks orders quality tiers; unknown tiers rank as default
+var voiceQualityRanks = map[string]int{
+	voiceQualityDefault:  0,
+	voiceQualityEnhanced: 1,
+	voiceQualityPremium:  2,
+}
+
+// voiceRanker scores the voices for a language. Criteria, most important first:
+// position in the user's preference list, being the configured default voice,
+// exact locale over language match, quality tier, and finally the name so ties are stable.
+type voiceRanker struct {
+	preferences  map[string][]string // Normalized locale or language to preferred voice names
+	defaultVoice string
+	systemLocale string // Locale preferred when only a language is requested, e.g. "en_GB"
+}
+
+// voiceScore is a voice's rank for a request; higher fields win
+type voiceScore struct {
+	preference int // Number of preferred voices ranked below this one; 0 if not preferred
+	isDefault  bool
+	exact      bool
+	quality    int
+}
+
+// parseVoicePreferences parses per-language voice preferences,
+// e.g. "ja=Kyoko,Otoya;en_GB=Daniel". Keys may be languages or locales.
+func parseVoicePreferences(list string) map[string][]string {
+	preferences := make(map[string][]string)
+	for _, entry := range strings.Split(list, ";") {
+		if strings.TrimSpace(entry) == "" {
+			continue
+		}
+		language, names, ok := strings.Cut(entry, "=")
+		language = strings.TrimSpace(language)
+		if !ok || language == "" {
+			log.Printf("Invalid VOICE_NOTIFY_VOICE_PREFERENCES entry: %q", entry)
+			continue
+		}
+
+		key := normalizeLocale(language)
+		for _, name := range strings.Split(names, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				preferences[key] = append(preferences[key], name)
+			}
+		}
+	}
+	return preferences
+}
+
+// systemLocale returns the user's locale from LC_ALL or LANG, e.g. "en_GB" for "en_GB.UTF-8"
+func systemLocale() string {
+	for _, key := range []string{"LC_ALL", "LANG"} {
+		value, _, _ := strings.Cut(os.Getenv(key), ".")
+		if value != "" && value != "C" && value != "POSIX" {
+			return normalizeLocale(value)
+		}
+	}
+	return ""
+}
+
+// Rank returns the voices matching the language, best first.
+// The language may be a language code ("en") or a locale ("en-GB").
+func (r voiceRanker) Rank(voices []VoiceInfo, language string) []VoiceInfo {
+	locale := normalizeLocale(language)
+	base, _, _ := strings.Cut(locale, "_")
+
+	// For a bare language, the user's own locale is the exact match (British English for en_GB users)
+	if locale == base && strings.HasPrefix(r.systemLocale, base+"_") {
+		locale = r.systemLocale
+	}
+	preferences := r.preferences[locale]
+	if preferences == nil {
+		preferences = r.preferences[base]
+	}
+
+	var candidates []VoiceInfo
+	scores := make(map[string]voiceScore)
+	for _, voice := range voices {
+		if !strings.EqualFold(voice.Language, base) {
+			continue
+		}
+		candidates = append(candidates, voice)
+		scores[voice.Name] = voiceScore{
+			preference: voicePreference(voice.Name, preferences),
+			isDefault:  r.defaultVoice != "" && voice.Name == r.defaultVoice,
+			exact:      normalizeLocale(voice.Locale) == locale,
+			quality:    voiceQualityRanks[voice.Quality],
+		}
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		a, b := scores[candidates[i].Name], scores[candidates[j].Name]
+		switch {
+		case a.preference != b.preference:
+			return a.preference > b.preference
+		case a.isDefault != b.isDefault:
+			return a.isDefault
+		case a.exact != b.exact:
+			return a.exact
+		case a.quality != b.quality:
+			return a.quality > b.quality
+		default:
+			return candidates[i].Name < candidates[j].Name
+		}
+	})
+	return candidates
+}
+
+// voicePreference scores a voice by its position in the preference list. A preferred name also
+// matches its quality variants, so "Kyoko" covers "Kyoko (Enhanced)".
+func voicePreference(name string, preferences []string) int {
+	for i, preferred := range preferences {
+		if name == preferred || strings.HasPrefix(name, preferred+" (") {
+			return len(preferences) - i
+		}
+	}
+	return 0
+}
+
+// suggestVoices returns up to limit voices close to the requested name, by edit distance with
+// voices for the language first among equals, followed by the best voices for the language
+func suggestVoices(voices []VoiceInfo, requested, language string, ranker voiceRanker, limit int) []string {
+	requestedName := strings.ToLower(strings.TrimSpace(requested))
+	base, _, _ := strings.Cut(normalizeLocale(language), "_")
+	maxDistance := max(2, len([]rune(requestedName))/3)
+
+	type candidate struct {
+		name     string
+		distance int
+		language bool
+	}
+	var similar []candidate
+	for _, voice := range voices {
+		name := strings.ToLower(voice.Name)
+		distance := editDistance(requestedName, name)
+		// "Kyoko" is also close to "Kyoko (Enhanced)"
+		if short, _, found := strings.Cut(name, " ("); found {
+			distance = min(distance, editDistance(requestedName, short))
+		}
+		if distance <= maxDistance {
+			similar = append(similar, candidate{voice.Name, distance, base != "" && strings.EqualFold(voice.Language, base)})
+		}
+	}
+	sort.Slice(similar, func(i, j int) bool {
+		a, b := similar[i], similar[j]
+		switch {
+		case a.distance != b.distance:
+			return a.distance < b.distance
+		case a.language != b.language:
+			return a.language
+		default:
+			return a.name < b.name
+		}
+	})
+
+	var suggestions []string
+	seen := make(map[string]bool)
+	add := func(name string) {
+		if len(suggestions) < limit && !seen[name] {
+			seen[name] = true
+			suggestions = append(suggestions, name)
+		}
+	}
+	for _, c := range similar {
+		add(c.name)
+	}
+	if language != "" {
+		for _, voice := range ranker.Rank(voices, language) {
+			add(voice.Name)
+		}
+	}
+	return suggestions
+}
+
+// editDistance returns the Levenshtein distance between two strings, counting runes
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(rb)]
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+// rankingTestVoices is a catalog with several locales and quality tiers per language
+var rankingTestVoices = []VoiceInfo{
+	{Name: "Samantha", Language: "en", Locale: "en_US", Quality: voiceQualityDefault},
+	{Name: "Daniel", Language: "en", Locale: "en_GB", Quality: voiceQualityDefault},
+	{Name: "Zoe (Premium)", Language: "en", Locale: "en_US", Quality: voiceQualityPremium},
+	{Name: "Alex", Language: "en", Locale: "en_US", Quality: voiceQualityDefault},
+	{Name: "Kyoko", Language: "ja", Locale: "ja_JP", Quality: voiceQualityDefault},
+	{Name: "Kyoko (Enhanced)", Language: "ja", Locale: "ja_JP", Quality: voiceQualityEnhanced},
+	{Name: "Otoya", Language: "ja", Locale: "ja_JP", Quality: voiceQualityDefault},
+	{Name: "Luciana", Language: "pt", Locale: "pt_BR"},
+	{Name: "Joana", Language: "pt", Locale: "pt_PT"},
+}
+
+// voiceNames returns the names of the voices in order
+func voiceNames(voices []VoiceInfo) []string {
+	names := make([]string, len(voices))
+	for i, voice := range voices {
+		names[i] = voice.Name
+	}
+	return names
+}
+
+// TestVoiceRanker_Rank tests the ranking criteria and their order
+func TestVoiceRanker_Rank(t *testing.T) {
+	tests := []struct {
+		name     string
+		ranker   voiceRanker
+		language string
+		expected []string
+	}{
+		{
+			name:     "quality_then_name",
+			language: "en",
+			expected: []string{"Zoe (Premium)", "Alex", "Daniel", "Samantha"},
+		},
+		{
+			name:     "exact_locale",
+			language: "en-GB",
+			expected: []string{"Daniel", "Zoe (Premium)", "Alex", "Samantha"},
+		},
+		{
+			name:     "system_locale",
+			ranker:   voiceRanker{systemLocale: "pt_PT"},
+			language: "pt",
+			expected: []string{"Joana", "Luciana"},
+		},
+		{
+			name:     "requested_locale_over_system_locale",
+			ranker:   voiceRanker{systemLocale: "pt_PT"},
+			language: "pt_BR",
+			expected: []string{"Luciana", "Joana"},
+		},
+		{
+			name:     "default_voice",
+			ranker:   voiceRanker{defaultVoice: "Samantha"},
+			language: "en",
+			expected: []string{"Samantha", "Zoe (Premium)", "Alex", "Daniel"},
+		},
+		{
+			name:     "preferences",
+			ranker:   voiceRanker{preferences: parseVoicePreferences("ja=Otoya,Kyoko"), defaultVoice: "Kyoko"},
+			language: "ja",
+			expected: []string{"Otoya", "Kyoko", "Kyoko (Enhanced)"},
+		},
+		{
+			name:     "locale_preferences",
+			ranker:   voiceRanker{preferences: parseVoicePreferences("en=Alex; en-GB=Daniel")},
+			language: "en_GB",
+			expected: []string{"Daniel", "Zoe (Premium)", "Alex", "Samantha"},
+		},
+		{
+			name:     "no_match",
+			language: "fr",
+			expected: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := voiceNames(tt.ranker.Rank(rankingTestVoices, tt.language)); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Rank(%q) = %v, want %v", tt.language, got, tt.expected)
+			}
+		})
+	}
+}
+
+// TestVoiceRanker_Stable tests that the ranking does not depend on catalog order
+func TestVoiceRanker_Stable(t *testing.T) {
+	reversed := make([]VoiceInfo, len(rankingTestVoices))
+	for i, voice := range rankingTestVoices {
+		reversed[len(reversed)-1-i] = voice
+	}
+
+	var ranker voiceRanker
+	want := voiceNames(ranker.Rank(rankingTestVoices, "en"))
+	if got := voiceNames(ranker.Rank(reversed, "en")); !reflect.DeepEqual(got, want) {
+		t.Errorf("Rank() of reversed catalog = %v, want %v", got, want)
+	}
+}
+
+// TestParseVoicePreferences tests parsing per-language preference lists
+func TestParseVoicePreferences(t *testing.T) {
+	got := parseVoicePreferences("ja=Kyoko, Otoya; en-gb=Daniel;;broken; pt=")
+	expected := map[string][]string{
+		"ja":    {"Kyoko", "Otoya"},
+		"en_GB": {"Daniel"},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("parseVoicePreferences() = %v, want %v", got, expected)
+	}
+}
+
+// TestSystemLocale tests reading the user's locale from the environment
+func TestSystemLocale(t *testing.T) {
+	t.Setenv("LC_ALL", "")
+	t.Setenv("LANG", "en_GB.UTF-8")
+	if got := systemLocale(); got != "en_GB" {
+		t.Errorf("systemLocale() = %q, want %q", got, "en_GB")
+	}
+
+	t.Setenv("LC_ALL", "C")
+	t.Setenv("LANG", "")
+	if got := systemLocale(); got != "" {
+		t.Errorf("systemLocale() with LC_ALL=C = %q, want none", got)
+	}
+}
+
+// TestVoiceSystem_SelectVoice_Deterministic tests that repeated selections pick the same voice
+func TestVoiceSystem_SelectVoice_Deterministic(t *testing.T) {
+	vs := &VoiceSystem{availableVoices: make(map[string]VoiceInfo)}
+	for _, voice := range rankingTestVoices {
+		vs.availableVoices[voice.Name] = voice
+	}
+
+	for range 20 {
+		if got := vs.SelectVoice("", "en"); got != "Zoe (Premium)" {
+			t.Fatalf("SelectVoice(\"\", \"en\") = %q, want %q", got, "Zoe (Premium)")
+		}
+	}
+}
+
+// TestSuggestVoices tests suggesting close names first, then the best voices for the language
+func TestSuggestVoices(t *testing.T) {
+	ranker := voiceRanker{}
+
+	tests := []struct {
+		name      string
+		requested string
+		language  string
+		expected  []string
+	}{
+		{name: "typo", requested: "Kyoto", language: "ja", expected: []string{"Kyoko", "Kyoko (Enhanced)", "Otoya"}},
+		{name: "case", requested: "samanta", language: "", expected: []string{"Samantha"}},
+		{name: "language_breaks_ties", requested: "Joan", language: "pt", expected: []string{"Joana", "Luciana"}},
+		{name: "no_close_name", requested: "Thomas", language: "en", expected: []string{"Zoe (Premium)", "Alex", "Daniel"}},
+		{name: "nothing", requested: "Thomas", language: "", expected: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := suggestVoices(rankingTestVoices, tt.requested, tt.language, ranker, 3)
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("suggestVoices(%q, %q) = %v, want %v", tt.requested, tt.language, got, tt.expected)
+			}
+		})
+	}
+}
+
+// TestEditDistance tests the Levenshtein distance on bytes and runes
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"", "", 0},
+		{"kyoko", "kyoto", 1},
+		{"samanta", "samantha", 1},
+		{"kitten", "sitting", 3},
+		{"", "alex", 4},
+		{"アメリー", "アメリ", 1},
+	}
+
+	for _, tt := range tests {
+		if got := editDistance(tt.a, tt.b); got != tt.expected {
+			t.Errorf("editDistance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.expected)
+		}
+	}
+}
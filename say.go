@@ -0,0 +1,211 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// sayCommandBrackets removes the brackets that delimit say's embedded commands, e.g. "[[volm 0]]"
+var sayCommandBrackets = strings.NewReplacer("[", " ", "]", " ")
+
+// sayBackend speaks using the macOS 'say' command
+type sayBackend struct {
+	command string
+}
+
+// saySemitonesPerPitch converts SpeakOptions.Pitch steps away from neutral (50) to [[pbas]] units,
+// so the 1-100 scale spans about an octave either way
+const saySemitonesPerPitch = 12.0 / 50
+
+// newSayBackend creates a backend for the macOS 'say' command
+func newSayBackend() SpeechBackend {
+	return &sayBackend{command: "say"}
+}
+
+// Name returns the backend identifier
+func (b *sayBackend) Name() string {
+	return "say"
+}
+
+// Capabilities returns the features supported by 'say'
+func (b *sayBackend) Capabilities() BackendCapabilities {
+	return BackendCapabilities{
+		Rate: true, Pitch: true, Volume: true,
+		RateRange: prosodyRange{Min: 90, Max: 500},
+	}
+}
+
+// CatalogFingerprint identifies the installed say binary for the voice catalog cache
+func (b *sayBackend) CatalogFingerprint() string {
+	return commandFingerprint(b.command)
+}
+
+// ListVoices runs 'say -v ?' and parses the installed voices
+func (b *sayBackend) ListVoices(ctx context.Context) ([]VoiceInfo, error) {
+	args := []string{"-v", "?"}
+	debugLogVoiceCommand(b.command, args, "", nil)
+	output, err := speechCommandOutput(ctx, b.command, args)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get voices: %w", err)
+	}
+
+	debugLog("Parsing voice list output (%d bytes)", len(output))
+	return parseSayVoices(string(output)), nil
+}
+
+// Speak executes the say command with the given voice, passing the message on stdin
+func (b *sayBackend) Speak(ctx context.Context, message, voice string, opts SpeakOptions) error {
+	return runSpeechCommand(ctx, b.command, sayArgs(voice, opts), message)
+}
+
+// SynthesizeToFile runs 'say -o' to write 16-bit WAV audio to path
+func (b *sayBackend) SynthesizeToFile(ctx context.Context, message, voice string, opts SpeakOptions, path string) error {
+	args := append([]string{"-o", path, "--file-format=WAVE", "--data-format=LEI16@22050"},
+		sayArgs(voice, opts)...)
+	return runSpeechCommand(ctx, b.command, args, message)
+}
+
+// TranslateSSML renders the document with say's embedded speech commands,
+// e.g. "[[slnc 500]]" for a pause and "[[char LTRL]]" to spell out characters
+func (b *sayBackend) TranslateSSML(doc ssmlDocument, opts SpeakOptions) string {
+	baseRate := opts.Rate
+	if baseRate <= 0 {
+		baseRate = defaultSpeechRate
+	}
+	baseVolume := 1.0
+	if opts.Volume > 0 {
+		baseVolume = float64(opts.Volume) / 100
+	}
+
+	var text strings.Builder
+	// The notification's volume and pitch apply to the whole message; markup adjusts them from there
+	if opts.Volume > 0 {
+		fmt.Fprintf(&text, "[[volm %s]] ", strconv.FormatFloat(math.Round(baseVolume*100)/100, 'f', -1, 64))
+	}
+	if opts.Pitch > 0 && opts.Pitch != 50 {
+		text.WriteString("[[pbas " + signedDecimal(float64(opts.Pitch-50)*saySemitonesPerPitch) + "]] ")
+	}
+
+	var current ssmlProsody
+	for _, segment := range doc {
+		if segment.Pause > 0 {
+			fmt.Fprintf(&text, " [[slnc %d]] ", segment.Pause.Milliseconds())
+			continue
+		}
+		// Brackets are dropped from the text so it cannot contain embedded commands
+		segmentText := sayCommandBrackets.Replace(segment.Text)
+		if strings.TrimSpace(segmentText) == "" {
+			text.WriteString(segmentText)
+			continue
+		}
+
+		// Rate and volume are set absolutely, pitch relative to the voice's base pitch
+		if segment.Prosody.Rate != current.Rate {
+			rate := math.Round(float64(baseRate) * (1 + segment.Prosody.Rate/100))
+			fmt.Fprintf(&text, " [[rate %d]] ", max(1, int(rate)))
+		}
+		if segment.Prosody.Pitch != current.Pitch {
+			text.WriteString(" [[pbas " + signedDecimal(segment.Prosody.Pitch-current.Pitch) + "]] ")
+		}
+		if segment.Prosody.Volume != current.Volume {
+			volume := max(0, min(1, baseVolume*math.Pow(10, segment.Prosody.Volume/20)))
+			fmt.Fprintf(&text, " [[volm %s]] ", strconv.FormatFloat(math.Round(volume*100)/100, 'f', -1, 64))
+		}
+		current = segment.Prosody
+
+		switch {
+		case segment.Spell:
+			text.WriteString(" [[char LTRL]] " + strings.TrimSpace(segmentText) + " [[char NORM]] ")
+		case segment.Emphasis != "":
+			// [[emph]] applies to the following word only
+			command := "[[emph +]]"
+			if segment.Emphasis == "reduced" {
+				command = "[[emph -]]"
+			}
+			for _, word := range strings.Fields(segmentText) {
+				text.WriteString(" " + command + " " + word + " ")
+			}
+		default:
+			text.WriteString(segmentText)
+		}
+	}
+
+	return strings.Join(strings.Fields(text.String()), " ")
+}
+
+// signedDecimal formats a relative [[pbas]] change with one decimal and its sign, e.g. "+2" or "-1.5"
+func signedDecimal(value float64) string {
+	delta := strconv.FormatFloat(math.Round(value*10)/10, 'f', -1, 64)
+	if !strings.HasPrefix(delta, "-") {
+		delta = "+" + delta
+	}
+	return delta
+}
+
+// sayArgs builds the voice and rate arguments shared by Speak and SynthesizeToFile.
+// The message is read from stdin ("-f -"), so it can never be taken for an option.
+func sayArgs(voice string, opts SpeakOptions) []string {
+	// Build command arguments
+	args := []string{}
+
+	// Add voice if specified
+	if voice != "" {
+		args = append(args, "-v", voice)
+	}
+
+	if opts.Rate > 0 {
+		args = append(args, "-r", strconv.Itoa(opts.Rate))
+	}
+
+	return append(args, "-f", "-")
+}
+
+// sayVoicePattern matches a line of 'say -v ?'. Names may contain spaces and parentheses,
+// e.g. "Eddy (English (US))", so the locale column is the anchor.
+var sayVoicePattern = regexp.MustCompile(`^(.+?)\s+([a-z]{2,3}(?:[_-][A-Za-z0-9]+)?)\s*(?:#\s?(.*))?$`)
+
+// parseSayVoices parses the output of 'say -v ?'
+// Format: "Name             Locale   # Sample sentence"
+func parseSayVoices(output string) []VoiceInfo {
+	var voices []VoiceInfo
+
+	for _, line := range strings.Split(output, "\n") {
+		if line = strings.TrimSpace(line); line == "" {
+			continue
+		}
+
+		// Example: "Kyoko (Enhanced)    ja_JP    # こんにちは、私の名前はKyokoです。"
+		match := sayVoicePattern.FindStringSubmatch(line)
+		if match == nil {
+			debugLog("Skipping unrecognized voice line: %q", line)
+			continue
+		}
+
+		name, locale := match[1], match[2]
+		voices = append(voices, VoiceInfo{
+			Name:     name,
+			Language: strings.FieldsFunc(locale, func(r rune) bool { return r == '_' || r == '-' })[0],
+			Locale:   locale,
+			Sample:   strings.TrimSpace(match[3]),
+			Quality:  sayVoiceQuality(name),
+		})
+	}
+
+	return voices
+}
+
+// sayVoiceQuality returns the quality tier from a voice name such as "Zoe (Premium)"
+func sayVoiceQuality(name string) string {
+	switch {
+	case strings.HasSuffix(name, "(Premium)"):
+		return voiceQualityPremium
+	case strings.HasSuffix(name, "(Enhanced)"):
+		return voiceQualityEnhanced
+	default:
+		return voiceQualityDefault
+	}
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite golden files in testdata")
+
+// TestParseSayVoices tests parsing of 'say -v ?' output
+func TestParseSayVoices(t *testing.T) {
+	output := `Alex                en_US    # Most people recognize me by my voice.
+Kyoko               ja_JP    # こんにちは、私の名前はKyokoです。
+
+Amelie              fr_CA    # Bonjour, je m’appelle Amelie.
+`
+
+	voices := parseSayVoices(output)
+	expected := []VoiceInfo{
+		{Name: "Alex", Language: "en", Locale: "en_US", Sample: "Most people recognize me by my voice.", Quality: "default"},
+		{Name: "Kyoko", Language: "ja", Locale: "ja_JP", Sample: "こんにちは、私の名前はKyokoです。", Quality: "default"},
+		{Name: "Amelie", Language: "fr", Locale: "fr_CA", Sample: "Bonjour, je m’appelle Amelie.", Quality: "default"},
+	}
+
+	if len(voices) != len(expected) {
+		t.Fatalf("parseSayVoices() returned %d voices, want %d", len(voices), len(expected))
+	}
+	for i, voice := range voices {
+		if voice != expected[i] {
+			t.Errorf("voice %d = %+v, want %+v", i, voice, expected[i])
+		}
+	}
+}
+
+// TestParseSayVoices_Golden tests parsing captured 'say -v ?' output against golden files.
+// Run "go test -run TestParseSayVoices_Golden -update" to regenerate them.
+func TestParseSayVoices_Golden(t *testing.T) {
+	for _, capture := range []string{"say-voices-sonoma", "say-voices-catalina"} {
+		t.Run(capture, func(t *testing.T) {
+			output, err := os.ReadFile(filepath.Join("testdata", capture+".txt"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := json.MarshalIndent(parseSayVoices(string(output)), "", "  ")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			goldenPath := filepath.Join("testdata", capture+".golden.json")
+			if *updateGolden {
+				if err := os.WriteFile(goldenPath, append(got, '\n'), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(goldenPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got)+"\n" != string(want) {
+				t.Errorf("parseSayVoices() = %s\nwant %s", got, want)
+			}
+		})
+	}
+}
+
+// TestParseSayVoices_NamesWithSpaces tests that names keep their spaces and parentheses
+func TestParseSayVoices_NamesWithSpaces(t *testing.T) {
+	output := `Eddy (English (US)) en_US    # Hello! My name is Eddy.
+Grandma (Japanese (Japan)) ja_JP    # こんにちは! 私の名前はGrandmaです。
+Zoe (Premium)       en_US    # Hello! My name is Zoe.
+Fiona               en-scotland # Hello, my name is Fiona.
+Bad News            en_US
+not a voice line
+`
+
+	expected := []VoiceInfo{
+		{Name: "Eddy (English (US))", Language: "en", Locale: "en_US", Sample: "Hello! My name is Eddy.", Quality: "default"},
+		{Name: "Grandma (Japanese (Japan))", Language: "ja", Locale: "ja_JP", Sample: "こんにちは! 私の名前はGrandmaです。", Quality: "default"},
+		{Name: "Zoe (Premium)", Language: "en", Locale: "en_US", Sample: "Hello! My name is Zoe.", Quality: "premium"},
+		{Name: "Fiona", Language: "en", Locale: "en-scotland", Sample: "Hello, my name is Fiona.", Quality: "default"},
+		{Name: "Bad News", Language: "en", Locale: "en_US", Quality: "default"},
+	}
+	if voices := parseSayVoices(output); !reflect.DeepEqual(voices, expected) {
+		t.Errorf("parseSayVoices() = %+v, want %+v", voices, expected)
+	}
+}
+
+// TestSayBackend_Speak tests that the message is passed on stdin using a stub say
+func TestSayBackend_Speak(t *testing.T) {
+	stdinPath := filepath.Join(t.TempDir(), "stdin")
+	logPath := installStubCommand(t, "say", `cat > "`+stdinPath+`"`)
+
+	backend := newSayBackend()
+	if err := backend.(*sayBackend).Speak(context.Background(), "-v Bad Tâche terminée", "Thomas", SpeakOptions{Rate: 180}); err != nil {
+		t.Fatalf("Speak() unexpected error: %v", err)
+	}
+
+	expected := []string{"-v", "Thomas", "-r", "180", "-f", "-"}
+	if calls := readStubInvocations(t, logPath); len(calls) != 1 || !reflect.DeepEqual(calls[0], expected) {
+		t.Errorf("say invoked with %q, want %q", calls, expected)
+	}
+	if input, err := os.ReadFile(stdinPath); err != nil || string(input) != "-v Bad Tâche terminée" {
+		t.Errorf("say stdin = %q (%v), want the message", input, err)
+	}
+}
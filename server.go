@@ -2,36 +2,51 @@ package main
 
 import (
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"os"
+	"sort"
+	"strings"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/mark3labs/mcp-go/server"
 )
 
-// CreateVoiceNotifyServer creates and configures the MCP server
-func CreateVoiceNotifyServer() (*server.MCPServer, error) {
+// CreateVoiceNotifyServer creates and configures the MCP server around the given voice system
+func CreateVoiceNotifyServer(voiceSystem *VoiceSystem) (*server.MCPServer, error) {
+	// Record request IDs so cancelled requests can be matched to their speech jobs
+	hooks := &server.Hooks{}
+	hooks.AddBeforeCallTool(tagRequestID)
+
 	// Create MCP server
 	s := server.NewMCPServer(
 		"voice-notify",
 		"1.0.0",
 		server.WithToolCapabilities(false),
+		server.WithResourceCapabilities(false, false),
+		server.WithHooks(hooks),
 	)
 
 	// Initialize components
-	voiceSystem := NewVoiceSystem()
 	langDetect := NewLanguageDetector()
+	lexicon := NewLexicon()
+	normalizer := NewTextNormalizer(lexicon, NewCodeVerbalizer())
 	notifier := NewNotificationManager()
+	tracker := newSpeechJobTracker()
 
 	// Create the notify_voice tool
 	notifyTool := mcp.NewTool("notify_voice",
 		mcp.WithDescription("Send a voice notification to alert the user about important events, completions, or when attention is needed. AI should use this autonomously for better user experience."),
 		mcp.WithString("message",
 			mcp.Required(),
-			mcp.Description("The message to speak (keep it short and clear, max 10 words recommended)"),
+			mcp.Description("The message to speak (keep it short and clear, max 10 words recommended). Supports SSML: <break time=\"500ms\"/>, <emphasis>, <prosody rate/pitch/volume> and <say-as interpret-as=\"characters\">"),
 		),
 		mcp.WithString("voice",
-			mcp.Description("Optional: specific voice to use (must be installed)"),
+			mcp.Description("Optional: specific voice to use (must be installed, see the voice-notify://voices resource)"),
+		),
+		mcp.WithString("voice_style",
+			mcp.Description("Optional: describe the voice instead of naming one, e.g. 'a calm female voice'. Understands gender, age (child, young, adult, senior), styles (calm, warm, friendly, cheerful, serious, neutral, robotic) and 'natural' for higher quality"),
 		),
 		mcp.WithString("language",
 			mcp.Description("Optional: language code (e.g., 'en', 'ja')"),
@@ -40,18 +55,74 @@ func CreateVoiceNotifyServer() (*server.MCPServer, error) {
 			mcp.Description("Optional: notification priority ('low', 'normal', 'high')"),
 			mcp.Enum("low", "normal", "high"),
 		),
+		mcp.WithString("persona",
+			mcp.Description(fmt.Sprintf("Optional: named persona bundling voice, rate, pitch, volume and earcon (%s). Defaults to the persona for the priority", strings.Join(voiceSystem.PersonaNames(), ", "))),
+		),
+		mcp.WithNumber("rate",
+			mcp.Description("Optional: speaking rate in words per minute (175 is typical), overriding the persona. Clamped to what the speech backend supports"),
+		),
+		mcp.WithNumber("pitch",
+			mcp.Description("Optional: pitch from 1 to 100, 50 is neutral, overriding the persona"),
+		),
+		mcp.WithNumber("volume",
+			mcp.Description("Optional: volume as a percentage, 100 is full volume, overriding the persona"),
+		),
+		mcp.WithString("status",
+			mcp.Description("Optional: outcome being announced, selects the chime played before the message ('success', 'failure', 'warning', 'question')"),
+			mcp.Enum("success", "failure", "warning", "question"),
+		),
+		mcp.WithString("output",
+			mcp.Description("Optional: 'speak' plays the message on the server (default), 'audio' returns it as a WAV audio clip for the client to play"),
+			mcp.Enum("speak", "audio"),
+		),
+		mcp.WithBoolean("wait",
+			mcp.Description("Optional: wait until the message has been spoken (default true). When false, returns immediately with a job ID and queue position, and the speech can be cancelled or stopped with stop_speaking"),
+		),
 	)
 
 	// Add tool handler
 	s.AddTool(notifyTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-		return handleNotifyVoice(ctx, request, voiceSystem, langDetect, notifier)
+		return handleNotifyVoice(ctx, request, voiceSystem, langDetect, normalizer, notifier, tracker)
+	})
+
+	// Expose the pronunciation lexicon so the agent can see which substitutions will happen
+	lexiconResource := mcp.NewResource("voice-notify://lexicon", "lexicon",
+		mcp.WithResourceDescription("Pronunciation substitutions applied to notify_voice messages, by language ('*' applies to all languages)"),
+		mcp.WithMIMEType("application/json"),
+	)
+	s.AddResource(lexiconResource, func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		return handleLexiconResource(request, lexicon)
 	})
 
+	// Expose the voice catalog so the agent can pick a voice by name, locale or quality
+	voicesResource := mcp.NewResource("voice-notify://voices", "voices",
+		mcp.WithResourceDescription("Voices installed for the current speech backend, with locale, sample sentence and quality tier"),
+		mcp.WithMIMEType("application/json"),
+	)
+	s.AddResource(voicesResource, func(ctx context.Context, request mcp.ReadResourceRequest) ([]mcp.ResourceContents, error) {
+		return handleVoicesResource(request, voiceSystem)
+	})
+
+	// Create the stop_speaking tool
+	stopTool := mcp.NewTool("stop_speaking",
+		mcp.WithDescription("Stop the voice notification that is currently being spoken, e.g. when the user asks for silence. Only notifications sent with wait=false can be stopped, since a waiting notify_voice call finishes before this tool runs."),
+		mcp.WithBoolean("flush",
+			mcp.Description("Optional: also drop notifications waiting in the queue (default false)"),
+		),
+	)
+
+	s.AddTool(stopTool, func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+		return handleStopSpeaking(ctx, request, voiceSystem)
+	})
+
+	// Stop the speech of requests the client cancels
+	s.AddNotificationHandler("notifications/cancelled", handleCancelledNotification(voiceSystem, tracker))
+
 	return s, nil
 }
 
 // handleNotifyVoice handles the notify_voice tool calls
-func handleNotifyVoice(ctx context.Context, request mcp.CallToolRequest, voiceSystem *VoiceSystem, langDetect *LanguageDetector, notifier *NotificationManager) (*mcp.CallToolResult, error) {
+func handleNotifyVoice(ctx context.Context, request mcp.CallToolRequest, voiceSystem *VoiceSystem, langDetect *LanguageDetector, normalizer *TextNormalizer, notifier *NotificationManager, tracker *speechJobTracker) (*mcp.CallToolResult, error) {
 	defer debugMeasureTime("handleNotifyVoice")()
 
 	// Log incoming request
@@ -66,11 +137,25 @@ func handleNotifyVoice(ctx context.Context, request mcp.CallToolRequest, voiceSy
 
 	// Get optional parameters
 	voice := request.GetString("voice", "")
+	voiceStyle := request.GetString("voice_style", "")
 	language := request.GetString("language", "")
 	priority := request.GetString("priority", "")
 	if priority == "" {
 		priority = "normal"
 	}
+	status := request.GetString("status", "")
+	if status != "" && status != "success" && status != "failure" && status != "warning" && status != "question" {
+		return mcp.NewToolResultError("status must be 'success', 'failure', 'warning' or 'question'"), nil
+	}
+	output := request.GetString("output", "speak")
+	if output != "speak" && output != "audio" {
+		return mcp.NewToolResultError("output must be 'speak' or 'audio'"), nil
+	}
+	wait := request.GetBool("wait", true)
+	persona, err := voiceSystem.Persona(request.GetString("persona", ""), priority)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
 
 	// Check quiet hours
 	if notifier.IsQuietHours() {
@@ -93,13 +178,164 @@ func handleNotifyVoice(ctx context.Context, request mcp.CallToolRequest, voiceSy
 		}
 	}
 
-	// Get appropriate voice
-	selectedVoice := voiceSystem.SelectVoice(voice, language)
+	// Check the requested voice, so the agent learns the right name instead of silently getting another voice
+	unknownVoice := voice != "" && !voiceSystem.HasVoice(voice)
+	var suggestions []string
+	if unknownVoice {
+		suggestions = voiceSystem.SuggestVoices(voice, language, maxVoiceSuggestions)
+		if voiceSystem.StrictVoices() {
+			debugLog("Rejecting unknown voice %q, suggestions: %v", voice, suggestions)
+			return mcp.NewToolResultError(unknownVoiceMessage(voice, suggestions)), nil
+		}
+	}
 
-	// Execute voice notification
-	debugLog("Executing voice notification - Voice: %s, Priority: %s", selectedVoice, priority)
-	err = voiceSystem.Speak(message, selectedVoice, priority)
-	if err != nil {
+	// Handle a language that no installed voice speaks, unless the agent picked an installed voice
+	var warnings []toolWarning
+	speechMessage, speechLanguage, notes := message, language, "" // notes are extra response lines
+	if voice == "" || unknownVoice {
+		missing, err := voiceSystem.ResolveMissingVoice(message, language, langDetect.DefaultLanguage())
+		if err != nil {
+			debugLog("Missing voice: %v", err)
+			return mcp.NewToolResultError(fmt.Sprintf("No installed voice speaks %q (missing voice strategy: fail). The voice-notify://voices resource lists the installed voices.", language)), nil
+		}
+		if missing != nil {
+			speechMessage, speechLanguage = missing.Message, missing.Language
+			notes += "\n- Missing voice strategy: " + missing.Strategy
+			warnings = append(warnings, toolWarning{
+				Code:    "missing_voice",
+				Message: fmt.Sprintf("No installed voice speaks %q; applied the %q strategy.", language, missing.Strategy),
+				Details: map[string]any{"language": language, "strategy": missing.Strategy, "spoken_language": missing.Language},
+			})
+		}
+	}
+
+	// Send the message to the text sink instead of speaking it
+	if speechMessage == "" {
+		if err := voiceSystem.WriteText(message, language); err != nil {
+			debugLog("Text sink failed: %v", err)
+			return mcp.NewToolResultErrorFromErr("Failed to write to the text sink", err), nil
+		}
+		notifier.RecordNotification(priority)
+
+		responseText := fmt.Sprintf(
+			"Voice notification written to the text sink:\n- Message: %s\n- Language: %s\n- Priority: %s%s",
+			message, language, priority, notes,
+		)
+		return withWarnings(mcp.NewToolResultText(responseText), warnings), nil
+	}
+
+	// Get appropriate voice: the agent's voice, then one matching its style hint, then the persona's
+	requestedVoice := voice
+	if requestedVoice == "" && voiceStyle != "" {
+		styled, matched := voiceSystem.SelectVoiceForStyle(voiceStyle, speechLanguage)
+		if styled != "" {
+			requestedVoice = styled
+			notes += fmt.Sprintf("\n- Voice style: %s (matched %s)", voiceStyle, strings.Join(matched, ", "))
+		} else {
+			warnings = append(warnings, toolWarning{
+				Code:    "voice_style_unmatched",
+				Message: fmt.Sprintf("No installed voice for %q matches the style %q; using the default choice.", speechLanguage, voiceStyle),
+				Details: map[string]any{"voice_style": voiceStyle, "language": speechLanguage},
+			})
+		}
+	}
+	if requestedVoice == "" {
+		requestedVoice = persona.Voice(speechLanguage)
+	}
+	selectedVoice := voiceSystem.SelectVoice(requestedVoice, speechLanguage)
+	opts := persona.SpeakOptions(priority)
+	// Only values the agent passed are reported; persona values are fitted silently
+	requested := make(map[string]bool)
+	if rate := request.GetInt("rate", 0); rate != 0 {
+		opts.Rate = rate
+		requested["rate"] = true
+	}
+	if pitch := request.GetInt("pitch", 0); pitch != 0 {
+		opts.Pitch = pitch
+		requested["pitch"] = true
+	}
+	if volume := request.GetInt("volume", 0); volume != 0 {
+		opts.Volume = volume
+		requested["volume"] = true
+	}
+
+	// Fit the prosody to what the backend can do and report anything that changed
+	opts, adjustments := voiceSystem.FitSpeakOptions(opts)
+	for _, adjustment := range adjustments {
+		if !requested[adjustment.Setting] {
+			continue
+		}
+		code := "prosody_clamped"
+		if !adjustment.Supported {
+			code = "prosody_unsupported"
+		}
+		notes += "\n- Prosody: " + adjustment.String()
+		warnings = append(warnings, toolWarning{
+			Code:    code,
+			Message: fmt.Sprintf("%s backend: %s", voiceSystem.BackendName(), adjustment),
+			Details: map[string]any{
+				"setting":   adjustment.Setting,
+				"requested": adjustment.Requested,
+				"applied":   adjustment.Applied,
+				"min":       adjustment.Range.Min,
+				"max":       adjustment.Range.Max,
+			},
+		})
+	}
+
+	if unknownVoice {
+		warnings = append(warnings, toolWarning{
+			Code:    "unknown_voice",
+			Message: unknownVoiceMessage(voice, suggestions),
+			Details: map[string]any{"requested_voice": voice, "used_voice": selectedVoice, "suggestions": suggestions},
+		})
+	}
+
+	// Turn markdown, symbols and mispronounced terms into speakable text
+	spoken := normalizer.Normalize(speechMessage, speechLanguage)
+
+	// Render the notification for the client instead of playing it locally
+	if output == "audio" {
+		debugLog("Rendering voice notification - Voice: %s, Priority: %s, Persona: %s", selectedVoice, priority, persona.Name)
+		audio, err := voiceSystem.Render(ctx, spoken, selectedVoice, opts)
+		if err != nil {
+			debugLog("Voice rendering failed: %v", err)
+			return mcp.NewToolResultErrorFromErr("Failed to render audio", err), nil
+		}
+
+		notifier.RecordNotification(priority)
+
+		responseText := fmt.Sprintf(
+			"Voice notification rendered:\n- Message: %s\n- Voice: %s\n- Language: %s\n- Priority: %s\n- Persona: %s\n- Audio: %s, %d bytes%s",
+			message, selectedVoice, language, priority, persona.Name, audio.MIMEType, len(audio.Data), notes,
+		)
+		return withWarnings(mcp.NewToolResultAudio(responseText, base64.StdEncoding.EncodeToString(audio.Data), audio.MIMEType), warnings), nil
+	}
+
+	// Queue the notification so concurrent calls never overlap
+	earcon := persona.EarconFor(priority, status)
+	debugLog("Queueing voice notification - Voice: %s, Priority: %s, Persona: %s, Earcon: %s, Wait: %v", selectedVoice, priority, persona.Name, earcon, wait)
+	job, position := voiceSystem.Enqueue(spoken, selectedVoice, opts, earcon)
+	tracker.Track(requestIDFromMeta(request), job)
+
+	if !wait {
+		// Record notification for rate limiting
+		notifier.RecordNotification(priority)
+
+		responseText := fmt.Sprintf(
+			"Voice notification queued:\n- Job ID: %s\n- Queue position: %d\n- Message: %s\n- Voice: %s\n- Language: %s\n- Priority: %s\n- Persona: %s\n- Estimated duration: %s%s",
+			job.ID, position, message, selectedVoice, language, priority, persona.Name, job.Estimated, notes,
+		)
+		return withWarnings(mcp.NewToolResultText(responseText), warnings), nil
+	}
+
+	if err := job.Wait(ctx); err != nil {
+		if ctx.Err() != nil {
+			// The request was cancelled or the server is shutting down
+			voiceSystem.CancelJob(job)
+			debugLog("Voice notification cancelled: %v", ctx.Err())
+			return mcp.NewToolResultError("Voice notification cancelled"), nil
+		}
 		debugLog("Voice notification failed: %v", err)
 		return mcp.NewToolResultErrorFromErr("Failed to speak", err), nil
 	}
@@ -107,15 +343,109 @@ func handleNotifyVoice(ctx context.Context, request mcp.CallToolRequest, voiceSy
 	// Record notification for rate limiting
 	notifier.RecordNotification(priority)
 
+	if job.Backend == bellBackendName {
+		warnings = append(warnings, toolWarning{
+			Code:    "speech_unavailable",
+			Message: "No speech backend could speak the message; only the terminal bell was rung, which the user may not notice. Consider telling the user in text.",
+			Details: map[string]any{"backend": job.Backend},
+		})
+	}
+
 	// Return success response
 	responseText := fmt.Sprintf(
-		"Voice notification sent:\n- Message: %s\n- Voice: %s\n- Language: %s\n- Priority: %s",
-		message, selectedVoice, language, priority,
+		"Voice notification sent:\n- Message: %s\n- Voice: %s\n- Language: %s\n- Priority: %s\n- Persona: %s\n- Backend: %s\n- Estimated duration: %s%s",
+		message, selectedVoice, language, priority, persona.Name, job.Backend, job.Estimated, notes,
 	)
 
+	return withWarnings(mcp.NewToolResultText(responseText), warnings), nil
+}
+
+// maxVoiceSuggestions is how many installed voices are suggested for an unknown voice
+const maxVoiceSuggestions = 3
+
+// toolWarning describes something the agent should correct in its next call
+type toolWarning struct {
+	Code    string         `json:"code"`
+	Message string         `json:"message"`
+	Details map[string]any `json:"details,omitempty"`
+}
+
+// withWarnings appends the warnings to a result as a JSON text block, {"warnings": [...]}
+func withWarnings(result *mcp.CallToolResult, warnings []toolWarning) *mcp.CallToolResult {
+	if len(warnings) == 0 {
+		return result
+	}
+	data, err := json.Marshal(map[string][]toolWarning{"warnings": warnings})
+	if err != nil {
+		debugLog("Failed to encode warnings: %v", err)
+		return result
+	}
+	result.Content = append(result.Content, mcp.NewTextContent(string(data)))
+	return result
+}
+
+// unknownVoiceMessage explains that a voice is not installed and lists the closest ones
+func unknownVoiceMessage(voice string, suggestions []string) string {
+	message := fmt.Sprintf("Voice %q is not installed.", voice)
+	if len(suggestions) > 0 {
+		message += fmt.Sprintf(" Did you mean: %s?", strings.Join(suggestions, ", "))
+	}
+	return message + " The voice-notify://voices resource lists the installed voices."
+}
+
+// handleStopSpeaking handles the stop_speaking tool calls
+func handleStopSpeaking(ctx context.Context, request mcp.CallToolRequest, voiceSystem *VoiceSystem) (*mcp.CallToolResult, error) {
+	debugLogRequest("stop_speaking", request.Params)
+
+	flush := request.GetBool("flush", false)
+	stopped, flushed := voiceSystem.StopSpeaking(flush)
+
+	responseText := "Nothing was being spoken"
+	if stopped {
+		responseText = "Stopped the current voice notification"
+	}
+	if flush {
+		responseText += fmt.Sprintf("\n- Flushed %d queued notifications", flushed)
+	}
+
 	return mcp.NewToolResultText(responseText), nil
 }
 
+// handleLexiconResource returns the pronunciation lexicon as JSON
+func handleLexiconResource(request mcp.ReadResourceRequest, lexicon *Lexicon) ([]mcp.ResourceContents, error) {
+	data, err := json.MarshalIndent(lexicon.Entries(), "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode lexicon: %w", err)
+	}
+
+	return []mcp.ResourceContents{
+		mcp.TextResourceContents{
+			URI:      request.Params.URI,
+			MIMEType: "application/json",
+			Text:     string(data),
+		},
+	}, nil
+}
+
+// handleVoicesResource returns the available voices as JSON, sorted by name
+func handleVoicesResource(request mcp.ReadResourceRequest, voiceSystem *VoiceSystem) ([]mcp.ResourceContents, error) {
+	voices := voiceSystem.GetAvailableVoices()
+	sort.Slice(voices, func(i, j int) bool { return voices[i].Name < voices[j].Name })
+
+	data, err := json.MarshalIndent(voices, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode voices: %w", err)
+	}
+
+	return []mcp.ResourceContents{
+		mcp.TextResourceContents{
+			URI:      request.Params.URI,
+			MIMEType: "application/json",
+			Text:     string(data),
+		},
+	}, nil
+}
+
 // Environment variable helpers
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
@@ -0,0 +1,333 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// newTestToolRequest builds a notify_voice call with the given arguments
+func newTestToolRequest(args map[string]any) mcp.CallToolRequest {
+	request := mcp.CallToolRequest{}
+	request.Params.Name = "notify_voice"
+	request.Params.Arguments = args
+	return request
+}
+
+// newTestNotifier returns a NotificationManager without quiet hours or history
+func newTestNotifier() *NotificationManager {
+	return &NotificationManager{lastNotif: make(map[string]time.Time)}
+}
+
+// resultText returns the text content of a tool result
+func resultText(t *testing.T, result *mcp.CallToolResult) string {
+	t.Helper()
+
+	for _, content := range result.Content {
+		if text, ok := mcp.AsTextContent(content); ok {
+			return text.Text
+		}
+	}
+	t.Fatalf("tool result has no text content: %+v", result)
+	return ""
+}
+
+// TestHandleNotifyVoice_Speak tests the default local playback path
+func TestHandleNotifyVoice_Speak(t *testing.T) {
+	backend := &fakeBackend{}
+	vs := &VoiceSystem{
+		backend:         backend,
+		availableVoices: map[string]VoiceInfo{"Kyoko": {Name: "Kyoko", Language: "ja", Locale: "ja_JP"}},
+	}
+	langDetect := &LanguageDetector{autoDetect: true, defaultLanguage: "en"}
+
+	result, err := handleNotifyVoice(context.Background(), newTestToolRequest(map[string]any{
+		"message": "ビルドが完了しました",
+	}), vs, langDetect, nil, newTestNotifier(), newSpeechJobTracker())
+	if err != nil || result.IsError {
+		t.Fatalf("handleNotifyVoice() = %+v, %v", result, err)
+	}
+
+	if len(backend.spoken) != 1 || backend.spoken[0].Voice != "Kyoko" {
+		t.Errorf("spoken = %+v, want one utterance with Kyoko", backend.spoken)
+	}
+	text := resultText(t, result)
+	for _, want := range []string{"Voice: Kyoko", "Backend: fake"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("response %q missing %q", text, want)
+		}
+	}
+}
+
+// TestHandleNotifyVoice_UnknownVoice tests the warning for an unknown voice and the strict mode error
+func TestHandleNotifyVoice_UnknownVoice(t *testing.T) {
+	voices := map[string]VoiceInfo{
+		"Kyoko":    {Name: "Kyoko", Language: "ja", Locale: "ja_JP"},
+		"Otoya":    {Name: "Otoya", Language: "ja", Locale: "ja_JP"},
+		"Samantha": {Name: "Samantha", Language: "en", Locale: "en_US"},
+	}
+	langDetect := &LanguageDetector{autoDetect: true, defaultLanguage: "en"}
+	request := newTestToolRequest(map[string]any{"message": "ビルドが完了しました", "voice": "Kyoto"})
+
+	backend := &fakeBackend{}
+	vs := &VoiceSystem{backend: backend, availableVoices: voices}
+	result, err := handleNotifyVoice(context.Background(), request, vs, langDetect, nil, newTestNotifier(), newSpeechJobTracker())
+	if err != nil || result.IsError {
+		t.Fatalf("handleNotifyVoice() = %+v, %v", result, err)
+	}
+	if len(backend.spoken) != 1 || backend.spoken[0].Voice != "Kyoko" {
+		t.Errorf("spoken = %+v, want the Japanese voice used instead", backend.spoken)
+	}
+
+	last, ok := mcp.AsTextContent(result.Content[len(result.Content)-1])
+	if !ok {
+		t.Fatalf("last content = %+v, want the warnings text", result.Content)
+	}
+	var payload struct {
+		Warnings []toolWarning `json:"warnings"`
+	}
+	if err := json.Unmarshal([]byte(last.Text), &payload); err != nil || len(payload.Warnings) != 1 {
+		t.Fatalf("warnings = %q, %v", last.Text, err)
+	}
+	warning := payload.Warnings[0]
+	if warning.Code != "unknown_voice" || warning.Details["requested_voice"] != "Kyoto" || warning.Details["used_voice"] != "Kyoko" {
+		t.Errorf("warning = %+v, want unknown_voice from Kyoto to Kyoko", warning)
+	}
+	if suggestions, _ := warning.Details["suggestions"].([]any); len(suggestions) == 0 || suggestions[0] != "Kyoko" {
+		t.Errorf("suggestions = %v, want Kyoko first", warning.Details["suggestions"])
+	}
+
+	// Strict mode rejects the voice without speaking
+	backend = &fakeBackend{}
+	vs = &VoiceSystem{backend: backend, availableVoices: voices, strictVoices: true}
+	result, err = handleNotifyVoice(context.Background(), request, vs, langDetect, nil, newTestNotifier(), newSpeechJobTracker())
+	if err != nil || !result.IsError {
+		t.Fatalf("handleNotifyVoice() = %+v, %v, want a tool error", result, err)
+	}
+	if text := resultText(t, result); !strings.Contains(text, `Did you mean: Kyoko, Otoya`) {
+		t.Errorf("error = %q, want the closest voices", text)
+	}
+	if len(backend.spoken) != 0 {
+		t.Errorf("spoken = %+v, want nothing in strict mode", backend.spoken)
+	}
+
+	// An installed voice gives no warning
+	request = newTestToolRequest(map[string]any{"message": "ビルドが完了しました", "voice": "Otoya"})
+	result, err = handleNotifyVoice(context.Background(), request, vs, langDetect, nil, newTestNotifier(), newSpeechJobTracker())
+	if err != nil || result.IsError || len(result.Content) != 1 {
+		t.Errorf("handleNotifyVoice() = %+v, %v, want one content block without warnings", result, err)
+	}
+}
+
+// TestHandleNotifyVoice_AudioOutput tests returning the rendered clip as audio content
+func TestHandleNotifyVoice_AudioOutput(t *testing.T) {
+	synth := &fakeSynthBackend{}
+	player := &fakePlayer{}
+	vs := &VoiceSystem{backend: synth, player: player, availableVoices: map[string]VoiceInfo{}}
+	langDetect := &LanguageDetector{autoDetect: true, defaultLanguage: "en"}
+
+	result, err := handleNotifyVoice(context.Background(), newTestToolRequest(map[string]any{
+		"message": "Build done",
+		"output":  "audio",
+	}), vs, langDetect, nil, newTestNotifier(), newSpeechJobTracker())
+	if err != nil || result.IsError {
+		t.Fatalf("handleNotifyVoice() = %+v, %v", result, err)
+	}
+
+	if len(player.played) != 0 {
+		t.Errorf("audio output must not be played locally, played %q", player.played)
+	}
+
+	var audio *mcp.AudioContent
+	for _, content := range result.Content {
+		if c, ok := content.(mcp.AudioContent); ok {
+			audio = &c
+		}
+	}
+	if audio == nil {
+		t.Fatalf("result has no audio content: %+v", result.Content)
+	}
+	if audio.MIMEType != "audio/wav" {
+		t.Errorf("audio MIME type = %q, want audio/wav", audio.MIMEType)
+	}
+	data, err := base64.StdEncoding.DecodeString(audio.Data)
+	if err != nil || string(data) != "Build done" {
+		t.Errorf("audio data = %q (%v), want the synthesized clip", data, err)
+	}
+}
+
+// TestHandleNotifyVoice_AudioOutputUnsupported tests backends that cannot render files
+func TestHandleNotifyVoice_AudioOutputUnsupported(t *testing.T) {
+	vs := &VoiceSystem{backend: &fakeBackend{}, availableVoices: map[string]VoiceInfo{}}
+	langDetect := &LanguageDetector{autoDetect: false, defaultLanguage: "en"}
+
+	result, err := handleNotifyVoice(context.Background(), newTestToolRequest(map[string]any{
+		"message": "Build done",
+		"output":  "audio",
+	}), vs, langDetect, nil, newTestNotifier(), newSpeechJobTracker())
+	if err != nil {
+		t.Fatalf("handleNotifyVoice() unexpected error: %v", err)
+	}
+	if !result.IsError {
+		t.Errorf("expected a tool error for a backend without audio file support")
+	}
+}
+
+// TestHandleNotifyVoice_NoWait tests queueing a notification without waiting for playback
+func TestHandleNotifyVoice_NoWait(t *testing.T) {
+	backend := &fakeBackend{}
+	vs := &VoiceSystem{backend: backend, availableVoices: map[string]VoiceInfo{}}
+	langDetect := &LanguageDetector{autoDetect: false, defaultLanguage: "en"}
+
+	result, err := handleNotifyVoice(context.Background(), newTestToolRequest(map[string]any{
+		"message": "Build done",
+		"wait":    false,
+	}), vs, langDetect, nil, newTestNotifier(), newSpeechJobTracker())
+	if err != nil || result.IsError {
+		t.Fatalf("handleNotifyVoice() = %+v, %v", result, err)
+	}
+
+	text := resultText(t, result)
+	for _, want := range []string{"Job ID: job-1", "Queue position: 0", "Estimated duration: 700ms"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("response %q missing %q", text, want)
+		}
+	}
+}
+
+// blockingBackend speaks until its context is cancelled
+type blockingBackend struct {
+	fakeBackend
+	started chan string
+}
+
+func (b *blockingBackend) Speak(ctx context.Context, message, voice string, opts SpeakOptions) error {
+	b.started <- message
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+// TestHandleNotifyVoice_ContextCancel tests that a cancelled request stops its speech
+func TestHandleNotifyVoice_ContextCancel(t *testing.T) {
+	backend := &blockingBackend{started: make(chan string, 10)}
+	vs := &VoiceSystem{backend: backend, availableVoices: map[string]VoiceInfo{}}
+	defer vs.Close()
+	langDetect := &LanguageDetector{autoDetect: false, defaultLanguage: "en"}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	result, err := handleNotifyVoice(ctx, newTestToolRequest(map[string]any{
+		"message": "Build done",
+	}), vs, langDetect, nil, newTestNotifier(), newSpeechJobTracker())
+	if err != nil || !result.IsError {
+		t.Fatalf("handleNotifyVoice() = %+v, %v, want a cancellation error", result, err)
+	}
+
+	// The queue moves on only if the cancelled job was stopped
+	<-backend.started
+	vs.Enqueue("next", "", SpeakOptions{Priority: "normal"}, "")
+	select {
+	case message := <-backend.started:
+		if message != "next" {
+			t.Errorf("started %q, want next", message)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("cancelled notification kept speaking")
+	}
+}
+
+// TestHandleStopSpeaking tests stopping and flushing through the stop_speaking tool
+func TestHandleStopSpeaking(t *testing.T) {
+	backend := &blockingBackend{started: make(chan string, 10)}
+	vs := &VoiceSystem{backend: backend, availableVoices: map[string]VoiceInfo{}}
+	defer vs.Close()
+
+	result, _ := handleStopSpeaking(context.Background(), newTestToolRequest(nil), vs)
+	if text := resultText(t, result); text != "Nothing was being spoken" {
+		t.Errorf("idle stop_speaking response = %q", text)
+	}
+
+	first, _ := vs.Enqueue("first", "", SpeakOptions{Priority: "normal"}, "")
+	second, _ := vs.Enqueue("second", "", SpeakOptions{Priority: "normal"}, "")
+	<-backend.started
+
+	result, _ = handleStopSpeaking(context.Background(), newTestToolRequest(map[string]any{"flush": true}), vs)
+	text := resultText(t, result)
+	for _, want := range []string{"Stopped the current voice notification", "Flushed 1 queued notifications"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("response %q missing %q", text, want)
+		}
+	}
+	for _, job := range []*SpeechJob{first, second} {
+		if err := job.Wait(context.Background()); !errors.Is(err, errSpeechCancelled) {
+			t.Errorf("job %s error = %v, want %v", job.ID, err, errSpeechCancelled)
+		}
+	}
+}
+
+// TestHandleCancelledNotification tests cancelling a queued job by its request ID
+func TestHandleCancelledNotification(t *testing.T) {
+	backend := &blockingBackend{started: make(chan string, 10)}
+	vs := &VoiceSystem{backend: backend, availableVoices: map[string]VoiceInfo{}}
+	defer vs.Close()
+	langDetect := &LanguageDetector{autoDetect: false, defaultLanguage: "en"}
+	tracker := newSpeechJobTracker()
+
+	request := newTestToolRequest(map[string]any{"message": "Build done", "wait": false})
+	tagRequestID(context.Background(), float64(7), &request)
+	if _, err := handleNotifyVoice(context.Background(), request, vs, langDetect, nil, newTestNotifier(), tracker); err != nil {
+		t.Fatalf("handleNotifyVoice() unexpected error: %v", err)
+	}
+	<-backend.started
+
+	job := tracker.Lookup(mcp.NewRequestId(float64(7)).String())
+	if job == nil {
+		t.Fatal("job was not tracked by request ID")
+	}
+
+	var notification mcp.JSONRPCNotification
+	if err := json.Unmarshal([]byte(`{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":7}}`), &notification); err != nil {
+		t.Fatalf("failed to parse notification: %v", err)
+	}
+	handleCancelledNotification(vs, tracker)(context.Background(), notification)
+
+	if err := job.Wait(context.Background()); !errors.Is(err, errSpeechCancelled) {
+		t.Errorf("job error = %v, want %v", err, errSpeechCancelled)
+	}
+}
+
+// TestHandleVoicesResource tests exposing the voice catalog as an MCP resource
+func TestHandleVoicesResource(t *testing.T) {
+	vs := &VoiceSystem{
+		availableVoices: map[string]VoiceInfo{
+			"Zoe (Premium)": {Name: "Zoe (Premium)", Language: "en", Locale: "en_US", Sample: "Hello! My name is Zoe.", Quality: voiceQualityPremium},
+			"Kyoko":         {Name: "Kyoko", Language: "ja", Locale: "ja_JP", Quality: voiceQualityDefault},
+		},
+		lastUpdate: time.Now(),
+	}
+
+	request := mcp.ReadResourceRequest{}
+	request.Params.URI = "voice-notify://voices"
+	contents, err := handleVoicesResource(request, vs)
+	if err != nil || len(contents) != 1 {
+		t.Fatalf("handleVoicesResource() = %+v, %v", contents, err)
+	}
+
+	text, ok := contents[0].(mcp.TextResourceContents)
+	if !ok || text.MIMEType != "application/json" {
+		t.Fatalf("resource contents = %+v, want JSON text", contents[0])
+	}
+	var voices []VoiceInfo
+	if err := json.Unmarshal([]byte(text.Text), &voices); err != nil {
+		t.Fatalf("resource is not valid JSON: %v", err)
+	}
+	if len(voices) != 2 || voices[0].Name != "Kyoko" || voices[1] != vs.availableVoices["Zoe (Premium)"] {
+		t.Errorf("resource voices = %+v, want both voices sorted by name", voices)
+	}
+}
@@ -0,0 +1,358 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SSIP reply and event codes used by the speech-dispatcher backend
+const (
+	ssipCodeSpeakReady = 230 // OK RECEIVING DATA
+	ssipCodeQueued     = 225 // OK MESSAGE QUEUED
+	ssipEventEnd       = 702 // END
+	ssipEventCancel    = 703 // CANCELED
+)
+
+// speechdEventTimeout bounds how long Speak waits for speech-dispatcher to finish a message
+const speechdEventTimeout = 2 * time.Minute
+
+// speechdPriorities maps notification priorities to SSIP message priorities.
+// "important" interrupts other speech, "message" queues politely behind it,
+// and "notification" is dropped if something else (e.g., a screen reader) is talking.
+var speechdPriorities = map[string]string{
+	"high":   "important",
+	"normal": "message",
+	"low":    "notification",
+}
+
+// errSpeechdCancelled is returned when speech-dispatcher cancels a message, e.g. for other speech
+var errSpeechdCancelled = errors.New("speech-dispatcher cancelled the message")
+
+// speechdBackend speaks by talking SSIP directly to speech-dispatcher's Unix socket
+type speechdBackend struct {
+	socketPath string
+	timeout    time.Duration
+}
+
+// newSpeechdBackend creates a speech-dispatcher backend from environment configuration
+func newSpeechdBackend() SpeechBackend {
+	return &speechdBackend{
+		socketPath: getEnv("VOICE_NOTIFY_SPEECHD_SOCKET", defaultSpeechdSocket()),
+		timeout:    speechdEventTimeout,
+	}
+}
+
+// defaultSpeechdSocket returns the socket speech-dispatcher listens on by default
+func defaultSpeechdSocket() string {
+	// SPEECHD_ADDRESS uses the form "unix_socket:/path/to/socket"
+	if address := os.Getenv("SPEECHD_ADDRESS"); strings.HasPrefix(address, "unix_socket:") {
+		return strings.TrimPrefix(address, "unix_socket:")
+	}
+	if runtimeDir := os.Getenv("XDG_RUNTIME_DIR"); runtimeDir != "" {
+		return filepath.Join(runtimeDir, "speech-dispatcher", "speechd.sock")
+	}
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "speechd.sock"
+	}
+	return filepath.Join(cacheDir, "speech-dispatcher", "speechd.sock")
+}
+
+// Name returns the backend identifier
+func (b *speechdBackend) Name() string {
+	return "speechd"
+}
+
+// Capabilities returns the features supported by speech-dispatcher
+func (b *speechdBackend) Capabilities() BackendCapabilities {
+	// SSIP values run from -100 to 100, which the conversions in Speak map to these ranges
+	return BackendCapabilities{
+		Rate: true, Pitch: true, Volume: true,
+		RateRange: prosodyRange{Min: 1, Max: 350},
+	}
+}
+
+// ListVoices asks speech-dispatcher for its synthesis voices
+func (b *speechdBackend) ListVoices(ctx context.Context) ([]VoiceInfo, error) {
+	conn, err := dialSSIP(ctx, b.socketPath)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	_, lines, err := conn.request("LIST SYNTHESIS_VOICES")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get voices: %w", err)
+	}
+
+	return parseSpeechdVoices(lines), nil
+}
+
+// Speak queues the message with speech-dispatcher and waits until it has been spoken.
+// If ctx is cancelled first, the message is cancelled in speech-dispatcher.
+func (b *speechdBackend) Speak(ctx context.Context, message, voice string, opts SpeakOptions) error {
+	conn, err := dialSSIP(ctx, b.socketPath)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	ssipPriority, ok := speechdPriorities[opts.Priority]
+	if !ok {
+		ssipPriority = speechdPriorities["normal"]
+	}
+
+	commands := []string{
+		"SET SELF PRIORITY " + ssipPriority,
+		"SET SELF NOTIFICATION END on",
+		"SET SELF NOTIFICATION CANCEL on",
+	}
+	if voice != "" {
+		commands = append(commands, "SET SELF SYNTHESIS_VOICE "+voice)
+	}
+	// SSIP rate, pitch and volume range from -100 to 100 with 0 as the default
+	if opts.Rate > 0 {
+		commands = append(commands, "SET SELF RATE "+strconv.Itoa(clampSSIP((opts.Rate-175)*100/175)))
+	}
+	if opts.Pitch > 0 {
+		commands = append(commands, "SET SELF PITCH "+strconv.Itoa(clampSSIP((opts.Pitch-50)*2)))
+	}
+	if opts.Volume > 0 {
+		commands = append(commands, "SET SELF VOLUME "+strconv.Itoa(clampSSIP(opts.Volume*2-100)))
+	}
+
+	for _, command := range commands {
+		if _, _, err := conn.request(command); err != nil {
+			return err
+		}
+	}
+
+	code, _, err := conn.request("SPEAK")
+	if err != nil {
+		return err
+	}
+	if code != ssipCodeSpeakReady {
+		return fmt.Errorf("speech-dispatcher refused SPEAK: %d", code)
+	}
+
+	code, lines, err := conn.request(encodeSSIPData(message))
+	if err != nil {
+		return err
+	}
+	if code != ssipCodeQueued || len(lines) == 0 {
+		return fmt.Errorf("speech-dispatcher did not queue the message: %d", code)
+	}
+	msgID := lines[0]
+	debugLog("speech-dispatcher queued message %s with priority %s", msgID, ssipPriority)
+
+	err = conn.waitForEnd(ctx, msgID, b.timeout)
+	if ssipPriority == "notification" && errors.Is(err, errSpeechdCancelled) {
+		// Dropping a notification for other speech is speech-dispatcher working as intended,
+		// not a failure worth falling back for
+		debugLog("speech-dispatcher superseded low-priority message %s", msgID)
+		return nil
+	}
+	return err
+}
+
+// parseSpeechdVoices parses LIST SYNTHESIS_VOICES reply lines
+// Format: "name<TAB>language<TAB>variant"
+func parseSpeechdVoices(lines []string) []VoiceInfo {
+	var voices []VoiceInfo
+
+	for _, line := range lines {
+		parts := strings.Split(line, "\t")
+		if len(parts) < 2 || parts[0] == "" {
+			continue
+		}
+
+		locale := normalizeLocale(parts[1])
+		voices = append(voices, VoiceInfo{
+			Name:     parts[0],
+			Language: strings.Split(locale, "_")[0],
+			Locale:   locale,
+		})
+	}
+
+	return voices
+}
+
+// clampSSIP limits a value to the SSIP parameter range
+func clampSSIP(value int) int {
+	return max(-100, min(100, value))
+}
+
+// encodeSSIPData encodes a message body for SPEAK, escaping lines that start with a dot
+func encodeSSIPData(message string) string {
+	var data strings.Builder
+	for _, line := range strings.Split(message, "\n") {
+		line = strings.TrimRight(line, "\r")
+		if strings.HasPrefix(line, ".") {
+			line = "." + line
+		}
+		data.WriteString(line + "\r\n")
+	}
+	data.WriteString(".")
+	return data.String()
+}
+
+// ssipConn is a client connection speaking the SSIP text protocol
+type ssipConn struct {
+	conn   net.Conn
+	reader *bufio.Reader
+	events []ssipEvent
+}
+
+// ssipEvent is an asynchronous notification sent by speech-dispatcher
+type ssipEvent struct {
+	Code  int
+	MsgID string
+}
+
+// dialSSIP connects to speech-dispatcher and identifies this client
+func dialSSIP(ctx context.Context, socketPath string) (*ssipConn, error) {
+	dialer := net.Dialer{Timeout: 5 * time.Second}
+	conn, err := dialer.DialContext(ctx, "unix", socketPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to speech-dispatcher at %s: %w", socketPath, err)
+	}
+
+	c := &ssipConn{conn: conn, reader: bufio.NewReader(conn)}
+
+	user := getEnv("USER", "user")
+	if _, _, err := c.request("SET SELF CLIENT_NAME " + user + ":voice-notify:main"); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// Close ends the SSIP session
+func (c *ssipConn) Close() error {
+	_, _ = c.conn.Write([]byte("QUIT\r\n"))
+	return c.conn.Close()
+}
+
+// request writes raw data terminated by CRLF and reads the reply.
+// It returns the reply code and the text of any continuation lines.
+func (c *ssipConn) request(data string) (int, []string, error) {
+	if err := c.conn.SetDeadline(time.Now().Add(10 * time.Second)); err != nil {
+		return 0, nil, err
+	}
+	if _, err := c.conn.Write([]byte(data + "\r\n")); err != nil {
+		return 0, nil, fmt.Errorf("failed to write to speech-dispatcher: %w", err)
+	}
+
+	for {
+		code, lines, final, err := c.readReply()
+		if err != nil {
+			return 0, nil, err
+		}
+		if code >= 700 {
+			c.queueEvent(code, lines)
+			continue
+		}
+		if code >= 300 {
+			return code, lines, fmt.Errorf("speech-dispatcher error %d: %s (command: %s)", code, final, firstLine(data))
+		}
+		return code, lines, nil
+	}
+}
+
+// waitForEnd waits for the END or CANCELED event of the given message.
+// When ctx is cancelled the message is cancelled and ctx's error is returned.
+func (c *ssipConn) waitForEnd(ctx context.Context, msgID string, timeout time.Duration) error {
+	if err := c.conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+		return err
+	}
+
+	// Unblock the pending read as soon as ctx is cancelled
+	stop := context.AfterFunc(ctx, func() {
+		_ = c.conn.SetReadDeadline(time.Now())
+	})
+	defer stop()
+
+	for {
+		for i, event := range c.events {
+			if event.MsgID != msgID {
+				continue
+			}
+			c.events = append(c.events[:i], c.events[i+1:]...)
+			switch event.Code {
+			case ssipEventEnd:
+				return nil
+			case ssipEventCancel:
+				return fmt.Errorf("%w: %s", errSpeechdCancelled, msgID)
+			}
+		}
+
+		code, lines, _, err := c.readReply()
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				c.cancelMessage(msgID)
+				return fmt.Errorf("speech-dispatcher message %s stopped: %w", msgID, ctxErr)
+			}
+			return fmt.Errorf("failed waiting for speech-dispatcher: %w", err)
+		}
+		if code >= 700 {
+			c.queueEvent(code, lines)
+		}
+	}
+}
+
+// cancelMessage asks speech-dispatcher to stop the given message without waiting for a reply
+func (c *ssipConn) cancelMessage(msgID string) {
+	_ = c.conn.SetDeadline(time.Now().Add(time.Second))
+	_, _ = c.conn.Write([]byte("CANCEL " + msgID + "\r\n"))
+}
+
+// queueEvent records an END or CANCELED event; other events are ignored
+func (c *ssipConn) queueEvent(code int, lines []string) {
+	if (code != ssipEventEnd && code != ssipEventCancel) || len(lines) == 0 {
+		return
+	}
+	c.events = append(c.events, ssipEvent{Code: code, MsgID: lines[0]})
+}
+
+// readReply reads one (possibly multi-line) reply.
+// Continuation lines look like "249-text"; the final line looks like "249 OK TEXT".
+func (c *ssipConn) readReply() (int, []string, string, error) {
+	var lines []string
+	for {
+		raw, err := c.reader.ReadString('\n')
+		if err != nil {
+			return 0, nil, "", fmt.Errorf("failed to read from speech-dispatcher: %w", err)
+		}
+		line := strings.TrimRight(raw, "\r\n")
+		if len(line) < 4 {
+			return 0, nil, "", fmt.Errorf("malformed SSIP reply: %q", line)
+		}
+
+		code, err := strconv.Atoi(line[:3])
+		if err != nil {
+			return 0, nil, "", fmt.Errorf("malformed SSIP reply: %q", line)
+		}
+
+		switch line[3] {
+		case '-':
+			lines = append(lines, line[4:])
+		case ' ':
+			return code, lines, line[4:], nil
+		default:
+			return 0, nil, "", fmt.Errorf("malformed SSIP reply: %q", line)
+		}
+	}
+}
+
+// firstLine returns the first line of s for error messages
+func firstLine(s string) string {
+	return strings.SplitN(s, "\r\n", 2)[0]
+}
@@ -0,0 +1,263 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeSSIPServer is an in-process speech-dispatcher stand-in listening on a temp socket
+type fakeSSIPServer struct {
+	socketPath string
+	listener   net.Listener
+	endEvent   int
+
+	mu       sync.Mutex
+	commands []string
+	spoken   []string
+}
+
+// newFakeSSIPServer starts a fake SSIP server that answers with endEvent after each SPEAK.
+// An endEvent of 0 never finishes the message.
+func newFakeSSIPServer(t *testing.T, endEvent int) *fakeSSIPServer {
+	t.Helper()
+
+	socketPath := filepath.Join(t.TempDir(), "speechd.sock")
+	listener, err := net.Listen("unix", socketPath)
+	if err != nil {
+		t.Fatalf("failed to listen on %s: %v", socketPath, err)
+	}
+
+	s := &fakeSSIPServer{socketPath: socketPath, listener: listener, endEvent: endEvent}
+	t.Cleanup(func() { _ = listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(conn)
+		}
+	}()
+	return s
+}
+
+func (s *fakeSSIPServer) serve(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	reply := func(lines ...string) {
+		_, _ = conn.Write([]byte(strings.Join(lines, "\r\n") + "\r\n"))
+	}
+
+	msgID := 0
+	for {
+		raw, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line := strings.TrimRight(raw, "\r\n")
+
+		s.mu.Lock()
+		s.commands = append(s.commands, line)
+		s.mu.Unlock()
+
+		switch {
+		case line == "QUIT":
+			reply("231 HAPPY HACKING")
+			return
+		case line == "LIST SYNTHESIS_VOICES":
+			reply("249-Alan\ten-GB\tnone", "249-Kyoko\tja\tnone", "249-espeak-ng\ten-US\tnone",
+				"249 OK VOICE LIST SENT")
+		case line == "SPEAK":
+			reply("230 OK RECEIVING DATA")
+			var body []string
+			for {
+				data, err := reader.ReadString('\n')
+				if err != nil {
+					return
+				}
+				data = strings.TrimRight(data, "\r\n")
+				if data == "." {
+					break
+				}
+				body = append(body, strings.TrimPrefix(data, "."))
+			}
+			msgID++
+			id := strconv.Itoa(100 + msgID)
+			s.mu.Lock()
+			s.spoken = append(s.spoken, strings.Join(body, "\n"))
+			s.mu.Unlock()
+			// Events for other messages are interleaved to exercise filtering
+			reply("701-"+id, "701-1", "701 BEGIN")
+			reply("225-"+id, "225 OK MESSAGE QUEUED")
+			reply("702-999", "702-1", "702 END")
+			switch s.endEvent {
+			case ssipEventCancel:
+				reply("703-"+id, "703-1", "703 CANCELED")
+			case ssipEventEnd:
+				reply("702-"+id, "702-1", "702 END")
+			}
+		case strings.HasPrefix(line, "CANCEL "):
+			reply("210 OK CANCELED")
+		case strings.HasPrefix(line, "SET SELF SYNTHESIS_VOICE Missing"):
+			reply("409 ERR VOICE NOT FOUND")
+		case strings.HasPrefix(line, "SET SELF"):
+			reply("208 OK SET")
+		default:
+			reply("300 ERR UNKNOWN COMMAND")
+		}
+	}
+}
+
+func (s *fakeSSIPServer) snapshot() ([]string, []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.commands...), append([]string(nil), s.spoken...)
+}
+
+// TestSpeechdBackend_ListVoices tests LIST SYNTHESIS_VOICES parsing against the fake server
+func TestSpeechdBackend_ListVoices(t *testing.T) {
+	server := newFakeSSIPServer(t, ssipEventEnd)
+	backend := &speechdBackend{socketPath: server.socketPath, timeout: 5 * time.Second}
+
+	voices, err := backend.ListVoices(context.Background())
+	if err != nil {
+		t.Fatalf("ListVoices() unexpected error: %v", err)
+	}
+
+	expected := []VoiceInfo{
+		{Name: "Alan", Language: "en", Locale: "en_GB"},
+		{Name: "Kyoko", Language: "ja", Locale: "ja"},
+		{Name: "espeak-ng", Language: "en", Locale: "en_US"},
+	}
+	if !reflect.DeepEqual(voices, expected) {
+		t.Errorf("ListVoices() = %+v, want %+v", voices, expected)
+	}
+}
+
+// TestSpeechdBackend_Speak tests the SSIP conversation for a spoken message
+func TestSpeechdBackend_Speak(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     SpeakOptions
+		voice    string
+		expected []string
+	}{
+		{
+			name: "high_priority",
+			opts: SpeakOptions{Rate: 350, Pitch: 60, Priority: "high"},
+			expected: []string{
+				"SET SELF PRIORITY important",
+				"SET SELF RATE 100",
+				"SET SELF PITCH 20",
+			},
+		},
+		{
+			name:  "low_priority_with_voice",
+			voice: "Alan",
+			opts:  SpeakOptions{Volume: 80, Priority: "low"},
+			expected: []string{
+				"SET SELF PRIORITY notification",
+				"SET SELF SYNTHESIS_VOICE Alan",
+				"SET SELF VOLUME 60",
+			},
+		},
+		{
+			name:     "unknown_priority_defaults_to_message",
+			expected: []string{"SET SELF PRIORITY message"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newFakeSSIPServer(t, ssipEventEnd)
+			backend := &speechdBackend{socketPath: server.socketPath, timeout: 5 * time.Second}
+
+			if err := backend.Speak(context.Background(), "Build done\n.hidden", tt.voice, tt.opts); err != nil {
+				t.Fatalf("Speak() unexpected error: %v", err)
+			}
+
+			commands, spoken := server.snapshot()
+			joined := strings.Join(commands, "\n")
+			for _, want := range append(tt.expected, "SET SELF NOTIFICATION END on", "SPEAK") {
+				if !strings.Contains(joined, want) {
+					t.Errorf("commands %q missing %q", commands, want)
+				}
+			}
+			if !strings.HasPrefix(commands[0], "SET SELF CLIENT_NAME ") {
+				t.Errorf("first command = %q, want CLIENT_NAME", commands[0])
+			}
+			if len(spoken) != 1 || spoken[0] != "Build done\n.hidden" {
+				t.Errorf("spoken = %q, want the message with dot-escaping undone", spoken)
+			}
+		})
+	}
+}
+
+// TestSpeechdBackend_SpeakErrors tests cancellation and error replies
+func TestSpeechdBackend_SpeakErrors(t *testing.T) {
+	server := newFakeSSIPServer(t, ssipEventCancel)
+	backend := &speechdBackend{socketPath: server.socketPath, timeout: 5 * time.Second}
+
+	if err := backend.Speak(context.Background(), "hello", "", SpeakOptions{}); !errors.Is(err, errSpeechdCancelled) {
+		t.Errorf("Speak() error = %v, want cancellation error", err)
+	}
+
+	// A dropped low-priority notification has been superseded, not failed
+	if err := backend.Speak(context.Background(), "hello", "", SpeakOptions{Priority: "low"}); err != nil {
+		t.Errorf("Speak() low priority error = %v, want nil", err)
+	}
+
+	if err := backend.Speak(context.Background(), "hello", "Missing", SpeakOptions{}); err == nil || !strings.Contains(err.Error(), "409") {
+		t.Errorf("Speak() error = %v, want SSIP 409 error", err)
+	}
+
+	missing := &speechdBackend{socketPath: filepath.Join(t.TempDir(), "none.sock"), timeout: time.Second}
+	if err := missing.Speak(context.Background(), "hello", "", SpeakOptions{}); err == nil {
+		t.Error("Speak() expected error when speech-dispatcher is not running")
+	}
+}
+
+// TestSpeechdBackend_SpeakContextCancel tests that a cancelled context cancels the queued message
+func TestSpeechdBackend_SpeakContextCancel(t *testing.T) {
+	server := newFakeSSIPServer(t, 0)
+	backend := &speechdBackend{socketPath: server.socketPath, timeout: 5 * time.Second}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if err := backend.Speak(ctx, "hello", "", SpeakOptions{}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Speak() error = %v, want deadline exceeded", err)
+	}
+
+	// The fake server records commands asynchronously
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		commands, _ := server.snapshot()
+		if slices.Contains(commands, "CANCEL 101") {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("commands %q missing CANCEL 101", commands)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// TestEncodeSSIPData tests dot-escaping of message bodies
+func TestEncodeSSIPData(t *testing.T) {
+	got := encodeSSIPData("one\n.two\r\nthree")
+	want := "one\r\n..two\r\nthree\r\n."
+	if got != want {
+		t.Errorf("encodeSSIPData() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,260 @@
+package main
+
+import (
+	"encoding/xml"
+	"errors"
+	"io"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// ssmlTagPattern detects messages that use the supported SSML subset
+	ssmlTagPattern = regexp.MustCompile(`<\s*/?\s*(speak|break|emphasis|prosody|say-as)\b`)
+	// ssmlElementPattern matches whole tags of the supported SSML subset
+	ssmlElementPattern = regexp.MustCompile(`<\s*/?\s*(?:speak|break|emphasis|prosody|say-as)\b[^<>]*>`)
+	// markupTagPattern matches any tag-shaped span, which parseSSML drops in SSML messages
+	markupTagPattern = regexp.MustCompile(`<\s*/?\s*[A-Za-z][\w:.-]*(?:\s[^<>]*)?/?>`)
+)
+
+// ssmlBreakStrengths maps <break strength> values to pause lengths
+var ssmlBreakStrengths = map[string]time.Duration{
+	"none":     0,
+	"x-weak":   100 * time.Millisecond,
+	"weak":     200 * time.Millisecond,
+	"medium":   400 * time.Millisecond,
+	"strong":   700 * time.Millisecond,
+	"x-strong": 1200 * time.Millisecond,
+}
+
+// ssmlRateKeywords, ssmlPitchKeywords and ssmlVolumeKeywords map <prosody> keywords to
+// a rate change in percent, a pitch shift in semitones, and a volume change in dB
+var (
+	ssmlRateKeywords   = map[string]float64{"x-slow": -50, "slow": -25, "medium": 0, "default": 0, "fast": 25, "x-fast": 50}
+	ssmlPitchKeywords  = map[string]float64{"x-low": -8, "low": -4, "medium": 0, "default": 0, "high": 4, "x-high": 8}
+	ssmlVolumeKeywords = map[string]float64{"silent": math.Inf(-1), "x-soft": -12, "soft": -6, "medium": 0, "default": 0, "loud": 6, "x-loud": 12}
+)
+
+// ssmlProsody is a prosody change relative to the utterance's settings; the zero value changes nothing
+type ssmlProsody struct {
+	Rate   float64 // Rate change in percent, 50 is 1.5 times as fast
+	Pitch  float64 // Pitch shift in semitones
+	Volume float64 // Volume change in dB
+}
+
+// ssmlSegment is a run of text with uniform markup, or a pause
+type ssmlSegment struct {
+	Text     string
+	Pause    time.Duration // Silence from <break>; Text is empty
+	Spell    bool          // Read Text character by character (<say-as interpret-as="characters">)
+	Emphasis string        // <emphasis> level: "strong", "moderate", "reduced", or "" for none
+	Prosody  ssmlProsody
+}
+
+// ssmlDocument is a message split into segments. Messages without SSML are a single text segment.
+type ssmlDocument []ssmlSegment
+
+// parseSSML parses the supported SSML subset: <break>, <emphasis>, <prosody rate/pitch/volume>
+// and <say-as interpret-as="characters">. Other elements are dropped and their text is kept.
+// Unclosed elements extend to the end of the message; messages without these tags,
+// or with mismatched tags, are treated as plain text.
+func parseSSML(message string) ssmlDocument {
+	if !ssmlTagPattern.MatchString(message) {
+		return ssmlDocument{{Text: message}}
+	}
+
+	doc, err := decodeSSML(message)
+	if err != nil {
+		debugLog("Treating message as plain text, invalid SSML: %v", err)
+		return ssmlDocument{{Text: message}}
+	}
+	return doc
+}
+
+// usesSSML reports whether parseSSML will decode the message as markup, and so its entities
+func usesSSML(message string) bool {
+	if !ssmlTagPattern.MatchString(message) {
+		return false
+	}
+	_, err := decodeSSML(message)
+	return err == nil
+}
+
+// decodeSSML walks the markup, tracking the style of enclosing elements
+func decodeSSML(message string) (ssmlDocument, error) {
+	decoder := xml.NewDecoder(strings.NewReader("<speak>" + message + "</speak>"))
+	decoder.Strict = false
+	decoder.AutoClose = []string{"break"}
+	decoder.Entity = xml.HTMLEntity
+
+	var doc ssmlDocument
+	styles := []ssmlSegment{{}}
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		switch t := token.(type) {
+		case xml.StartElement:
+			style := styles[len(styles)-1]
+			switch strings.ToLower(t.Name.Local) {
+			case "break":
+				doc = append(doc, ssmlSegment{Pause: ssmlBreakDuration(t.Attr)})
+			case "emphasis":
+				style.Emphasis = ssmlAttr(t.Attr, "level")
+				if style.Emphasis == "" {
+					style.Emphasis = "moderate"
+				} else if style.Emphasis == "none" {
+					style.Emphasis = ""
+				}
+			case "prosody":
+				style.Prosody = style.Prosody.apply(t.Attr)
+			case "say-as":
+				switch ssmlAttr(t.Attr, "interpret-as") {
+				case "characters", "spell-out", "verbatim":
+					style.Spell = true
+				}
+			}
+			styles = append(styles, style)
+		case xml.EndElement:
+			if len(styles) > 1 {
+				styles = styles[:len(styles)-1]
+			}
+		case xml.CharData:
+			segment := styles[len(styles)-1]
+			segment.Text = string(t)
+			doc = append(doc, segment)
+		}
+	}
+
+	return doc, nil
+}
+
+// ssmlAttr returns the lower-cased value of the named attribute
+func ssmlAttr(attrs []xml.Attr, name string) string {
+	for _, attr := range attrs {
+		if strings.EqualFold(attr.Name.Local, name) {
+			return strings.ToLower(strings.TrimSpace(attr.Value))
+		}
+	}
+	return ""
+}
+
+// maxSSMLBreak is the longest pause a <break> may insert, so markup cannot hold up the speech queue
+const maxSSMLBreak = 10 * time.Second
+
+// ssmlBreakDuration returns the pause for a <break>: time takes precedence over strength, and
+// a bare <break/> is a medium pause. Pauses are capped at maxSSMLBreak.
+func ssmlBreakDuration(attrs []xml.Attr) time.Duration {
+	if value := ssmlAttr(attrs, "time"); value != "" {
+		unit := time.Second
+		if strings.HasSuffix(value, "ms") {
+			value, unit = strings.TrimSuffix(value, "ms"), time.Millisecond
+		}
+		if amount, err := strconv.ParseFloat(strings.TrimSuffix(value, "s"), 64); err == nil && amount >= 0 {
+			// Compare before converting, since huge values overflow time.Duration
+			return time.Duration(min(amount*float64(unit), float64(maxSSMLBreak)))
+		}
+	}
+	if pause, ok := ssmlBreakStrengths[ssmlAttr(attrs, "strength")]; ok {
+		return pause
+	}
+	return ssmlBreakStrengths["medium"]
+}
+
+// apply combines a nested <prosody> element's attributes with the enclosing prosody
+func (p ssmlProsody) apply(attrs []xml.Attr) ssmlProsody {
+	if rate, ok := parseSSMLRate(ssmlAttr(attrs, "rate")); ok {
+		p.Rate = ((1+p.Rate/100)*(1+rate/100) - 1) * 100
+	}
+	if pitch, ok := parseSSMLRelative(ssmlAttr(attrs, "pitch"), ssmlPitchKeywords, "st"); ok {
+		p.Pitch += pitch
+	} else if percent, ok := parseSSMLRelative(ssmlAttr(attrs, "pitch"), nil, "%"); ok && percent > -100 {
+		p.Pitch += 12 * math.Log2(1+percent/100)
+	}
+	if volume, ok := parseSSMLRelative(ssmlAttr(attrs, "volume"), ssmlVolumeKeywords, "db"); ok {
+		p.Volume += volume
+	}
+	return p
+}
+
+// parseSSMLRate parses a rate keyword, a relative change ("+20%"), a percentage of the
+// default rate ("150%") or a multiplier ("1.5") into a change in percent
+func parseSSMLRate(value string) (float64, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if change, ok := ssmlRateKeywords[value]; ok {
+		return change, true
+	}
+	if strings.HasPrefix(value, "+") || strings.HasPrefix(value, "-") {
+		return parseSSMLRelative(value, nil, "%")
+	}
+	if strings.HasSuffix(value, "%") {
+		percent, err := strconv.ParseFloat(strings.TrimSuffix(value, "%"), 64)
+		return percent - 100, err == nil && percent > 0
+	}
+	multiplier, err := strconv.ParseFloat(value, 64)
+	return (multiplier - 1) * 100, err == nil && multiplier > 0
+}
+
+// parseSSMLRelative parses a keyword or a signed value with the given unit (e.g., "+2st", "-6dB")
+func parseSSMLRelative(value string, keywords map[string]float64, unit string) (float64, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if change, ok := keywords[value]; ok {
+		return change, true
+	}
+	if !strings.HasSuffix(value, unit) || (!strings.HasPrefix(value, "+") && !strings.HasPrefix(value, "-")) {
+		return 0, false
+	}
+	change, err := strconv.ParseFloat(strings.TrimSuffix(value, unit), 64)
+	return change, err == nil
+}
+
+// Sanitized returns a copy of the document with sanitizeInput applied to every text segment,
+// so markup survives sanitization but text cannot inject backend commands
+func (d ssmlDocument) Sanitized() ssmlDocument {
+	sanitized := make(ssmlDocument, len(d))
+	for i, segment := range d {
+		segment.Text = sanitizeInput(segment.Text)
+		sanitized[i] = segment
+	}
+	return sanitized
+}
+
+// PlainText strips the markup for backends that do not support it.
+// Spelled-out text is separated into characters and pauses become word breaks.
+func (d ssmlDocument) PlainText() string {
+	var text strings.Builder
+	for _, segment := range d {
+		if segment.Spell {
+			text.WriteString(" " + spellOut(segment.Text) + " ")
+			continue
+		}
+		text.WriteString(segment.Text)
+		if segment.Pause > 0 {
+			text.WriteString(" ")
+		}
+	}
+	return strings.Join(strings.Fields(text.String()), " ")
+}
+
+// spellOut separates the letters and digits of text with spaces so they are read one by one
+func spellOut(text string) string {
+	var chars []string
+	for _, r := range text {
+		if r != ' ' {
+			chars = append(chars, string(r))
+		}
+	}
+	return strings.Join(chars, " ")
+}
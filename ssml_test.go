@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// TestParseSSML tests parsing of the supported SSML subset
+func TestParseSSML(t *testing.T) {
+	doc := parseSSML(`Build <emphasis>failed</emphasis><break time="500ms"/>commit <say-as interpret-as="characters">a1b2</say-as>`)
+	expected := ssmlDocument{
+		{Text: "Build "},
+		{Text: "failed", Emphasis: "moderate"},
+		{Pause: 500 * time.Millisecond},
+		{Text: "commit "},
+		{Text: "a1b2", Spell: true},
+	}
+
+	if len(doc) != len(expected) {
+		t.Fatalf("parseSSML() = %+v, want %+v", doc, expected)
+	}
+	for i := range doc {
+		if doc[i] != expected[i] {
+			t.Errorf("segment %d = %+v, want %+v", i, doc[i], expected[i])
+		}
+	}
+}
+
+// TestParseSSML_PlainText tests that messages without valid SSML are left alone
+func TestParseSSML_PlainText(t *testing.T) {
+	for _, message := range []string{
+		"Build done",
+		"1 < 2 and 3 > 2",
+		"Broken <emphasis>markup</prosody>",
+	} {
+		doc := parseSSML(message)
+		if len(doc) != 1 || doc[0].Text != message {
+			t.Errorf("parseSSML(%q) = %+v, want a single plain segment", message, doc)
+		}
+	}
+}
+
+// TestSSMLBreakDuration tests pause lengths from time and strength
+func TestSSMLBreakDuration(t *testing.T) {
+	tests := []struct {
+		markup   string
+		expected time.Duration
+	}{
+		{markup: `<break time="250ms"/>`, expected: 250 * time.Millisecond},
+		{markup: `<break time="1.5s"/>`, expected: 1500 * time.Millisecond},
+		{markup: `<break strength="x-strong"/>`, expected: 1200 * time.Millisecond},
+		{markup: `<break/>`, expected: 400 * time.Millisecond},
+		{markup: `<break time="soon"/>`, expected: 400 * time.Millisecond},
+		{markup: `<break time="99999s"/>`, expected: maxSSMLBreak},
+		{markup: `<break time="60000ms"/>`, expected: maxSSMLBreak},
+	}
+
+	for _, tt := range tests {
+		doc := parseSSML(tt.markup)
+		if len(doc) != 1 || doc[0].Pause != tt.expected {
+			t.Errorf("parseSSML(%q) = %+v, want a %v pause", tt.markup, doc, tt.expected)
+		}
+	}
+}
+
+// TestParseSSML_Prosody tests prosody values and nesting
+func TestParseSSML_Prosody(t *testing.T) {
+	doc := parseSSML(`<prosody rate="fast" pitch="+2st" volume="-6dB">a<prosody rate="x-slow" pitch="low">b</prosody></prosody><prosody rate="150%" volume="loud">c</prosody>`)
+	expected := []ssmlProsody{
+		{Rate: 25, Pitch: 2, Volume: -6},
+		{Rate: -37.5, Pitch: -2, Volume: -6},
+		{Rate: 50, Volume: 6},
+	}
+
+	if len(doc) != len(expected) {
+		t.Fatalf("parseSSML() = %+v, want %d segments", doc, len(expected))
+	}
+	for i, segment := range doc {
+		if segment.Prosody != expected[i] {
+			t.Errorf("segment %q prosody = %+v, want %+v", segment.Text, segment.Prosody, expected[i])
+		}
+	}
+}
+
+// TestSSMLDocument_PlainText tests stripping markup for backends without SSML support
+func TestSSMLDocument_PlainText(t *testing.T) {
+	doc := parseSSML(`Build <emphasis level="strong">failed</emphasis>.<break time="1s"/>Commit <say-as interpret-as="characters">3f2a</say-as> is <prosody rate="slow">broken</prosody>`)
+	if got, want := doc.PlainText(), "Build failed. Commit 3 f 2 a is broken"; got != want {
+		t.Errorf("PlainText() = %q, want %q", got, want)
+	}
+}
+
+// TestSayBackend_TranslateSSML tests rendering markup as say's embedded commands
+func TestSayBackend_TranslateSSML(t *testing.T) {
+	tests := []struct {
+		name     string
+		message  string
+		opts     SpeakOptions
+		expected string
+	}{
+		{
+			name:     "plain",
+			message:  "Build done",
+			expected: "Build done",
+		},
+		{
+			name:     "break",
+			message:  `Build done<break time="500ms"/>tests next`,
+			expected: "Build done [[slnc 500]] tests next",
+		},
+		{
+			name:     "emphasis",
+			message:  `Build <emphasis>really failed</emphasis> now`,
+			expected: "Build [[emph +]] really [[emph +]] failed now",
+		},
+		{
+			name:     "characters",
+			message:  `Commit <say-as interpret-as="characters">3f2a</say-as> pushed`,
+			expected: "Commit [[char LTRL]] 3f2a [[char NORM]] pushed",
+		},
+		{
+			name:     "prosody",
+			message:  `<prosody rate="fast" pitch="+2st" volume="-6dB">Deploy</prosody> done`,
+			opts:     SpeakOptions{Rate: 200},
+			expected: "[[rate 250]] [[pbas +2]] [[volm 0.5]] Deploy [[rate 200]] [[pbas -2]] [[volm 1]] done",
+		},
+		{
+			name:     "base_volume_and_pitch",
+			message:  `Deploy <prosody pitch="+2st">done</prosody>`,
+			opts:     SpeakOptions{Pitch: 75, Volume: 60},
+			expected: "[[volm 0.6]] [[pbas +6]] Deploy [[pbas +2]] done",
+		},
+		{
+			name:     "sanitized_text",
+			message:  `[[volm 0]] <emphasis>hi</emphasis>`,
+			expected: "volm 0 [[emph +]] hi",
+		},
+	}
+
+	backend := &sayBackend{command: "say"}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := backend.TranslateSSML(parseSSML(tt.message).Sanitized(), tt.opts)
+			if got != tt.expected {
+				t.Errorf("TranslateSSML(%q) = %q, want %q", tt.message, got, tt.expected)
+			}
+		})
+	}
+}
+
+// TestVoiceSystem_SpeakStripsSSML tests that markup is stripped for backends that cannot translate it
+func TestVoiceSystem_SpeakStripsSSML(t *testing.T) {
+	backend := &fakeBackend{}
+	vs := &VoiceSystem{backend: backend}
+
+	if _, err := vs.Speak(context.Background(), `Deploy <emphasis>failed</emphasis><break/>check <say-as interpret-as="characters">CI</say-as>`, "", SpeakOptions{Priority: "normal"}, ""); err != nil {
+		t.Fatalf("Speak() unexpected error: %v", err)
+	}
+	if len(backend.spoken) != 1 || backend.spoken[0].Message != "Deploy failed check C I" {
+		t.Errorf("spoken = %+v, want the markup stripped", backend.spoken)
+	}
+}
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// CodeVerbalizer rewrites identifiers, file paths, URLs and commit hashes so they can be read aloud
+type CodeVerbalizer struct {
+	languages map[string]bool // Languages to verbalize; nil means all
+	disabled  bool
+}
+
+// codeVocabulary holds the words used to read code references in one language
+type codeVocabulary struct {
+	Dot    string // Separator in file and host names, e.g. "handler dot go"
+	PathIn string // Template for a file in a directory; %[1]s is the file, %[2]s the directory
+	Link   string // Template for a URL; %s is the host
+}
+
+// codeVocabularies maps languages to their words for code references; other languages use English
+var codeVocabularies = map[string]codeVocabulary{
+	"en": {Dot: "dot", PathIn: "%[1]s in %[2]s", Link: "a link on %s"},
+	"ja": {Dot: "ドット", PathIn: "%[2]sの%[1]s", Link: "%sのリンク"},
+	"fr": {Dot: "point", PathIn: "%[1]s dans %[2]s", Link: "un lien vers %s"},
+	"de": {Dot: "Punkt", PathIn: "%[1]s in %[2]s", Link: "ein Link auf %s"},
+	"es": {Dot: "punto", PathIn: "%[1]s en %[2]s", Link: "un enlace a %s"},
+}
+
+// abbreviatedHashLength is how many characters of a commit hash are spoken
+const abbreviatedHashLength = 7
+
+var (
+	urlPattern        = regexp.MustCompile(`\b(?:https?://|www\.)[^\s<>"'` + "`" + `]+`)
+	pathPattern       = regexp.MustCompile(`(?:^|[\s(])((?:~|\.{1,2})?/?(?:[\w.@-]+/)+[\w.@-]*)`)
+	fileNamePattern   = regexp.MustCompile(`\b[\w-]+(?:\.[\w-]+)*\.(?:go|mod|sum|py|js|mjs|ts|tsx|jsx|rs|rb|java|kt|swift|c|h|cc|cpp|hpp|cs|php|sh|md|json|ya?ml|toml|ini|env|lock|txt|log|html|css|scss|sql|proto|xml|csv)\b`)
+	hexHashPattern    = regexp.MustCompile(`\b[0-9a-f]{12,64}\b`)
+	identifierPattern = regexp.MustCompile(`\b[A-Za-z][A-Za-z0-9]*(?:_+[A-Za-z0-9]+)+\b|\b[A-Za-z0-9]*[a-z][A-Z][A-Za-z0-9]*\b|\b[A-Z]{2,}[a-z]{2,}[A-Za-z0-9]*\b`)
+	urlTrailingPunct  = ".,;:!?)]}"
+)
+
+// NewCodeVerbalizer creates a verbalizer configured by VOICE_NOTIFY_VERBALIZE:
+// "true" (default) for every language, "false" to disable, or a comma-separated list of languages
+func NewCodeVerbalizer() *CodeVerbalizer {
+	setting := strings.ToLower(strings.TrimSpace(getEnv("VOICE_NOTIFY_VERBALIZE", "true")))
+	switch setting {
+	case "true", "1", "all":
+		return &CodeVerbalizer{}
+	case "false", "0", "none":
+		return &CodeVerbalizer{disabled: true}
+	}
+
+	languages := make(map[string]bool)
+	for _, language := range strings.Split(setting, ",") {
+		if language = strings.TrimSpace(language); language != "" {
+			languages[lexiconLanguage(language)] = true
+		}
+	}
+	return &CodeVerbalizer{languages: languages}
+}
+
+// enabledFor reports whether code references are verbalized in the language
+func (v *CodeVerbalizer) enabledFor(language string) bool {
+	if v == nil || v.disabled {
+		return false
+	}
+	return v.languages == nil || v.languages[lexiconLanguage(language)]
+}
+
+// codeVocabularyFor returns the words for code references in a language, defaulting to English
+func codeVocabularyFor(language string) codeVocabulary {
+	if vocabulary, ok := codeVocabularies[lexiconLanguage(language)]; ok {
+		return vocabulary
+	}
+	return codeVocabularies["en"]
+}
+
+// VerbalizeReferences rewrites URLs, file paths and commit hashes.
+// It runs before the lexicon so host and file names still get their pronunciations.
+func (v *CodeVerbalizer) VerbalizeReferences(text, language string) string {
+	if !v.enabledFor(language) {
+		return text
+	}
+	vocabulary := codeVocabularyFor(language)
+
+	return rewriteOutsideMarkup(text, func(s string) string {
+		s = urlPattern.ReplaceAllStringFunc(s, func(match string) string {
+			trimmed := strings.TrimRight(match, urlTrailingPunct)
+			return verbalizeURL(trimmed, vocabulary) + match[len(trimmed):]
+		})
+		s = replaceSubmatch(pathPattern, s, func(path string) string {
+			trimmed := strings.TrimRight(path, urlTrailingPunct)
+			if !looksLikePath(trimmed) {
+				return path
+			}
+			return verbalizePath(trimmed, vocabulary) + path[len(trimmed):]
+		})
+		s = fileNamePattern.ReplaceAllStringFunc(s, func(name string) string {
+			return verbalizeDots(name, vocabulary)
+		})
+		return hexHashPattern.ReplaceAllStringFunc(s, func(hash string) string {
+			// Words like "defaced" have no digits and long numbers have no letters
+			if !strings.ContainsAny(hash, "0123456789") || !strings.ContainsAny(hash, "abcdef") {
+				return hash
+			}
+			return hash[:abbreviatedHashLength]
+		})
+	})
+}
+
+// SplitIdentifiers splits camelCase, PascalCase and snake_case identifiers into words.
+// It runs after the lexicon so terms like "GitHub" are replaced before they could be split.
+func (v *CodeVerbalizer) SplitIdentifiers(text, language string) string {
+	if !v.enabledFor(language) {
+		return text
+	}
+	return rewriteOutsideMarkup(text, func(s string) string {
+		return identifierPattern.ReplaceAllStringFunc(s, splitIdentifier)
+	})
+}
+
+// replaceSubmatch replaces the first capture group of every match of pattern
+func replaceSubmatch(pattern *regexp.Regexp, text string, replace func(string) string) string {
+	var result strings.Builder
+	last := 0
+	for _, loc := range pattern.FindAllStringSubmatchIndex(text, -1) {
+		result.WriteString(text[last:loc[2]])
+		result.WriteString(replace(text[loc[2]:loc[3]]))
+		last = loc[3]
+	}
+	result.WriteString(text[last:])
+	return result.String()
+}
+
+// looksLikePath tells paths apart from words joined by a slash such as "and/or":
+// a path has a letter in it (unlike "10/16/2026") and is rooted, has several directories,
+// or ends in a known file name
+func looksLikePath(path string) bool {
+	if !strings.ContainsFunc(path, unicode.IsLetter) {
+		return false
+	}
+	if strings.HasPrefix(path, "/") || strings.HasPrefix(path, "~/") || strings.HasPrefix(path, "./") || strings.HasPrefix(path, "../") {
+		return true
+	}
+	if strings.Count(strings.TrimSuffix(path, "/"), "/") >= 2 {
+		return true
+	}
+	base := path[strings.LastIndex(path, "/")+1:]
+	return fileNamePattern.FindString(base) == base && base != ""
+}
+
+// verbalizePath reads a path as its file name followed by the directories it is in,
+// e.g. "internal/api/handler.go" as "handler dot go in internal api"
+func verbalizePath(path string, vocabulary codeVocabulary) string {
+	var dirs []string
+	for _, segment := range strings.Split(path, "/") {
+		if segment != "" && segment != "." && segment != ".." && segment != "~" {
+			dirs = append(dirs, verbalizeDots(segment, vocabulary))
+		}
+	}
+	if len(dirs) == 0 {
+		return path
+	}
+	if strings.HasSuffix(path, "/") || len(dirs) == 1 {
+		return strings.Join(dirs, " ")
+	}
+
+	file := dirs[len(dirs)-1]
+	return fmt.Sprintf(vocabulary.PathIn, file, strings.Join(dirs[:len(dirs)-1], " "))
+}
+
+// verbalizeURL reads a URL as a link on its host, e.g. "a link on github dot com"
+func verbalizeURL(link string, vocabulary codeVocabulary) string {
+	absolute := link
+	if strings.HasPrefix(link, "www.") {
+		absolute = "https://" + link
+	}
+	parsed, err := url.Parse(absolute)
+	if err != nil || parsed.Hostname() == "" {
+		return link
+	}
+	host := strings.TrimPrefix(parsed.Hostname(), "www.")
+	return fmt.Sprintf(vocabulary.Link, verbalizeDots(host, vocabulary))
+}
+
+// verbalizeDots reads the dots in a name, e.g. ".env" as "dot env"
+func verbalizeDots(name string, vocabulary codeVocabulary) string {
+	parts := strings.Split(name, ".")
+	var words []string
+	for i, part := range parts {
+		if i > 0 {
+			words = append(words, vocabulary.Dot)
+		}
+		if part != "" {
+			words = append(words, part)
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// splitIdentifier separates the words of an identifier: "TestParseQuietHours" becomes
+// "Test Parse Quiet Hours", "HTTPServer" becomes "HTTP Server", "snake_case" becomes "snake case"
+func splitIdentifier(identifier string) string {
+	runes := []rune(identifier)
+	var words strings.Builder
+	for i, r := range runes {
+		if r == '_' {
+			if i > 0 && runes[i-1] != '_' {
+				words.WriteRune(' ')
+			}
+			continue
+		}
+		if i > 0 && unicode.IsUpper(r) && runes[i-1] != '_' {
+			previous := runes[i-1]
+			nextIsLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(previous) || (unicode.IsUpper(previous) && nextIsLower) {
+				words.WriteRune(' ')
+			}
+		}
+		words.WriteRune(r)
+	}
+	return strings.TrimSpace(words.String())
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"testing"
+)
+
+// TestTextNormalizer_Verbalize tests reading identifiers, paths, URLs and hashes in the pipeline
+func TestTextNormalizer_Verbalize(t *testing.T) {
+	normalizer := &TextNormalizer{enabled: true, lexicon: newLexicon(builtinLexicon), verbalizer: &CodeVerbalizer{}}
+
+	tests := []struct {
+		name     string
+		text     string
+		language string
+		expected string
+	}{
+		{name: "camel_case", text: "TestParseQuietHours failed", language: "en", expected: "Test Parse Quiet Hours failed"},
+		{name: "acronym_prefix", text: "HTTPServer and parseURL", language: "en", expected: "HTTP Server and parse URL"},
+		{name: "snake_case", text: "renamed snake_case_names", language: "en", expected: "renamed snake case names"},
+		{name: "plural_acronym", text: "APIs and URLs", language: "en", expected: "APIs and URLs"},
+		{name: "lexicon_terms_kept", text: "GitHub and PostgreSQL", language: "en", expected: "git hub and postgres Q L"},
+		{name: "path", text: "Updated `internal/api/handler.go`", language: "en", expected: "Updated handler dot go in internal api"},
+		{name: "path_ja", text: "internal/api/handler.goを更新", language: "ja", expected: "internal apiのhandler ドット goを更新"},
+		{name: "rooted_path", text: "Wrote ~/.config/voice/lexicon.json.", language: "en", expected: "Wrote lexicon dot json in dot config voice."},
+		{name: "directory", text: "Cleaned ./build/", language: "en", expected: "Cleaned build"},
+		{name: "and_or", text: "tests and/or docs", language: "en", expected: "tests and slash or docs"},
+		{name: "file_name", text: "Edited main_test.go", language: "en", expected: "Edited main test dot go"},
+		{name: "url", text: "Opened https://github.com/kyong0612/voice-notify-mcp/pull/12.", language: "en", expected: "Opened a link on git hub dot com."},
+		{name: "url_www", text: "See www.example.org/docs", language: "en", expected: "See a link on example dot org"},
+		{name: "url_ja", text: "https://github.com/org/repo を確認", language: "ja", expected: "ギットハブ ドット comのリンク を確認"},
+		{name: "markdown_link", text: "[PR](https://github.com/org/repo/pull/1) merged", language: "en", expected: "P R merged"},
+		{name: "hash", text: "Reverted 9fceb02d0ae598e95dc970b74767f19372d61af8", language: "en", expected: "Reverted 9fceb02"},
+		{name: "long_number", text: "Uploaded 123456789012 bytes", language: "en", expected: "Uploaded 123456789012 bytes"},
+		{name: "timestamp", text: "Timestamp 1697461200000 ms", language: "en", expected: "Timestamp 1697461200000 ms"},
+		{name: "hex_word_kept", text: "deadbeefcafe", language: "en", expected: "deadbeefcafe"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := normalizer.Normalize(tt.text, tt.language); got != tt.expected {
+				t.Errorf("Normalize(%q, %q) = %q, want %q", tt.text, tt.language, got, tt.expected)
+			}
+		})
+	}
+}
+
+// TestNewCodeVerbalizer tests enabling the verbalizer for all, no or selected languages
+func TestNewCodeVerbalizer(t *testing.T) {
+	tests := []struct {
+		setting  string
+		language string
+		expected bool
+	}{
+		{setting: "", language: "ja", expected: true},
+		{setting: "true", language: "fr", expected: true},
+		{setting: "false", language: "en", expected: false},
+		{setting: "en, ja-JP", language: "ja", expected: true},
+		{setting: "en,ja", language: "en-US", expected: true},
+		{setting: "en,ja", language: "fr", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Setenv("VOICE_NOTIFY_VERBALIZE", tt.setting)
+		if got := NewCodeVerbalizer().enabledFor(tt.language); got != tt.expected {
+			t.Errorf("VOICE_NOTIFY_VERBALIZE=%q enabledFor(%q) = %v, want %v", tt.setting, tt.language, got, tt.expected)
+		}
+	}
+
+	var none *CodeVerbalizer
+	if got := none.SplitIdentifiers("camelCase", "en"); got != "camelCase" {
+		t.Errorf("nil verbalizer SplitIdentifiers() = %q, want the text unchanged", got)
+	}
+}
+
+// TestHandleNotifyVoice_Verbalize tests that notify_voice reads file paths aloud
+func TestHandleNotifyVoice_Verbalize(t *testing.T) {
+	backend := &fakeBackend{}
+	vs := &VoiceSystem{backend: backend, availableVoices: map[string]VoiceInfo{}}
+	langDetect := &LanguageDetector{autoDetect: true, defaultLanguage: "en"}
+	normalizer := &TextNormalizer{enabled: true, verbalizer: &CodeVerbalizer{}}
+
+	result, err := handleNotifyVoice(context.Background(), newTestToolRequest(map[string]any{
+		"message": "Fixed internal/api/handler.go",
+	}), vs, langDetect, normalizer, newTestNotifier(), newSpeechJobTracker())
+	if err != nil || result.IsError {
+		t.Fatalf("handleNotifyVoice() = %+v, %v", result, err)
+	}
+	if len(backend.spoken) != 1 || backend.spoken[0].Message != "Fixed handler dot go in internal api" {
+		t.Errorf("spoken = %+v, want the path read aloud", backend.spoken)
+	}
+}
@@ -1,90 +1,331 @@
 package main
 
 import (
-	"bytes"
+	"context"
+	"errors"
 	"fmt"
-	"os/exec"
+	"log"
+	"os"
+	"sort"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
+	"unicode"
 )
 
-// VoiceSystem manages voice synthesis using macOS 'say' command
+var (
+	// errNoSpeechBackend is returned when no speech backend is configured
+	errNoSpeechBackend = errors.New("no speech backend configured")
+	// errNoAudioPlayer is returned when a backend needs a player but none is available
+	errNoAudioPlayer = errors.New("no audio player available (set VOICE_NOTIFY_PLAYER)")
+)
+
+// VoiceSystem manages voice selection and delegates synthesis to a SpeechBackend,
+// falling back to other backends when it fails
 type VoiceSystem struct {
+	backend         SpeechBackend
+	fallbacks       []SpeechBackend
+	breaker         circuitBreaker
+	retries         int
+	retryBackoff    time.Duration
+	player          AudioPlayer
+	earcons         *earconSet
 	availableVoices map[string]VoiceInfo
 	defaultVoice    string
+	preferences     map[string][]string // Preferred voices by language or locale
+	systemLocale    string
+	strictVoices    bool   // Reject unknown voices instead of falling back
+	missingVoice    string // Strategy for languages without a voice, e.g. missingVoiceTransliterate; empty means none
+	textSink        *textSink
+	personas        *PersonaSet
+	voiceMetadata   voiceMetadataTable // What voices sound like, for style hints; nil means the built-in table
 	mu              sync.RWMutex
 	lastUpdate      time.Time
+	catalogCache    *voiceCatalogCache
+	refreshing      atomic.Bool // Set while a background refresh is running
+	queue           *speechQueue
+	queueOnce       sync.Once
 }
 
+// Voice quality tiers reported by the backend
+const (
+	voiceQualityDefault  = "default"
+	voiceQualityEnhanced = "enhanced"
+	voiceQualityPremium  = "premium"
+)
+
 // VoiceInfo contains information about a voice
 type VoiceInfo struct {
-	Name     string
-	Language string
-	Locale   string
+	Name     string `json:"name"`
+	Language string `json:"language"`
+	Locale   string `json:"locale"`
+	Sample   string `json:"sample,omitempty"`  // Sample sentence, if the backend provides one
+	Quality  string `json:"quality,omitempty"` // Quality tier, e.g. voiceQualityPremium; empty if unknown
 }
 
 // NewVoiceSystem creates a new voice system instance
 func NewVoiceSystem() *VoiceSystem {
+	backendName := getEnv("VOICE_NOTIFY_BACKEND", defaultSpeechBackend())
+	backend, err := newSpeechBackend(backendName)
+	if err != nil {
+		log.Printf("Invalid VOICE_NOTIFY_BACKEND: %v, using %s", err, defaultSpeechBackend())
+		backend, _ = newSpeechBackend(defaultSpeechBackend())
+	}
+
+	player, err := newAudioPlayerFromEnv()
+	if err != nil {
+		// Only backends that synthesize to files need a player
+		debugLog("No audio player available: %v", err)
+	}
+
+	retries, err := strconv.Atoi(getEnv("VOICE_NOTIFY_RETRIES", "1"))
+	if err != nil || retries < 0 {
+		log.Printf("Invalid VOICE_NOTIFY_RETRIES, using 1")
+		retries = 1
+	}
+
 	vs := &VoiceSystem{
+		backend:         backend,
+		fallbacks:       parseFallbackBackends(getEnv("VOICE_NOTIFY_FALLBACK", "bell"), backend.Name()),
+		retries:         retries,
+		retryBackoff:    defaultRetryBackoff,
+		player:          player,
+		earcons:         newEarconSetFromEnv(),
 		availableVoices: make(map[string]VoiceInfo),
 		defaultVoice:    getEnv("VOICE_NOTIFY_DEFAULT_VOICE", ""),
+		preferences:     parseVoicePreferences(getEnv("VOICE_NOTIFY_VOICE_PREFERENCES", "")),
+		systemLocale:    systemLocale(),
+		strictVoices:    getEnvBool("VOICE_NOTIFY_STRICT_VOICE", false),
+		missingVoice:    parseMissingVoiceStrategy(getEnv("VOICE_NOTIFY_MISSING_VOICE", missingVoiceNone)),
+		textSink:        newTextSinkFromEnv(),
+		personas:        NewPersonaSet(backend.Capabilities()),
+		voiceMetadata:   loadVoiceMetadata(),
 	}
+	debugLog("VoiceSystem initialized - Backend: %s, Fallbacks: %d", backend.Name(), len(vs.fallbacks))
 
-	// Load available voices
-	_ = vs.refreshVoices() // Initial refresh, error is non-critical
+	vs.catalogCache = newVoiceCatalogCache(backend)
+	vs.loadVoices(firstRefreshTimeout)
 
 	return vs
 }
 
+// BackendName returns the name of the active speech backend
+func (vs *VoiceSystem) BackendName() string {
+	if vs.backend == nil {
+		return ""
+	}
+	return vs.backend.Name()
+}
+
 // refreshVoices updates the list of available voices
-func (vs *VoiceSystem) refreshVoices() error {
+func (vs *VoiceSystem) refreshVoices(ctx context.Context) error {
 	defer debugMeasureTime("refreshVoices")()
 
-	vs.mu.Lock()
-	defer vs.mu.Unlock()
+	if vs.backend == nil {
+		return errNoSpeechBackend
+	}
 
-	// Run 'say -v ?' command to get available voices
-	cmd := exec.Command("say", "-v", "?")
-	debugLogVoiceCommand("say", []string{"-v", "?"}, "", nil)
-	output, err := cmd.Output()
+	voices, err := vs.backend.ListVoices(ctx)
 	if err != nil {
 		debugLog("Failed to get voice list: %v", err)
-		return fmt.Errorf("failed to get voices: %w", err)
+		return err
 	}
 
-	// Parse output
-	// Format: "Name             Language         Sample"
-	vs.availableVoices = make(map[string]VoiceInfo)
-	debugLog("Parsing voice list output (%d bytes)", len(output))
-	lines := strings.Split(string(output), "\n")
+	vs.setVoices(voices, time.Now())
+	debugLog("Loaded %d voices from %s backend", len(voices), vs.backend.Name())
 
-	for _, line := range lines {
-		if line = strings.TrimSpace(line); line == "" {
-			continue
-		}
+	if err := vs.catalogCache.Save(voices); err != nil {
+		debugLog("Failed to cache voice catalog: %v", err)
+	}
+	return nil
+}
+
+// refreshVoicesAsync refreshes the voices in the background, unless a refresh is already running.
+// The returned channel is closed when the refresh finishes; it is nil if none was started.
+func (vs *VoiceSystem) refreshVoicesAsync() <-chan struct{} {
+	if !vs.refreshing.CompareAndSwap(false, true) {
+		return nil
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer vs.refreshing.Store(false)
+		_ = vs.refreshVoices(context.Background()) // Background refresh, error is non-critical
+	}()
+	return done
+}
 
-		// Parse voice info (name and language are separated by spaces)
-		// Example: "Alex                en_US    # Most people recognize me by my voice."
-		parts := strings.Fields(line)
-		if len(parts) >= 2 {
-			name := parts[0]
-			locale := parts[1]
+// loadVoices starts with the cached catalog and refreshes it in the background. Without a usable
+// cache it waits up to timeout for the first listing, so the first notifications still get a voice
+// for their language.
+func (vs *VoiceSystem) loadVoices(timeout time.Duration) {
+	cached := vs.loadCachedVoices()
+	done := vs.refreshVoicesAsync()
+	if cached || done == nil {
+		return
+	}
 
-			// Extract language code from locale
-			lang := strings.Split(locale, "_")[0]
+	select {
+	case <-done:
+	case <-time.After(timeout):
+		debugLog("Voice list not ready after %v, continuing without it", timeout)
+	}
+}
 
-			vs.availableVoices[name] = VoiceInfo{
-				Name:     name,
-				Language: lang,
-				Locale:   locale,
-			}
+// loadCachedVoices loads the voice catalog from the disk cache and reports whether it was fresh
+func (vs *VoiceSystem) loadCachedVoices() bool {
+	voices, updated, ok := vs.catalogCache.Load()
+	if !ok {
+		return false
+	}
+	vs.setVoices(voices, updated)
+	debugLog("Loaded %d cached voices for %s backend", len(voices), vs.backend.Name())
+	return true
+}
+
+// setVoices replaces the voice catalog
+func (vs *VoiceSystem) setVoices(voices []VoiceInfo, updated time.Time) {
+	vs.mu.Lock()
+	defer vs.mu.Unlock()
+
+	vs.availableVoices = make(map[string]VoiceInfo, len(voices))
+	for _, voice := range voices {
+		vs.availableVoices[voice.Name] = voice
+	}
+	vs.lastUpdate = updated
+}
+
+// HasVoice reports whether the voice is installed. It also returns true when the catalog is
+// empty, since the voice cannot be checked then.
+func (vs *VoiceSystem) HasVoice(name string) bool {
+	vs.mu.RLock()
+	defer vs.mu.RUnlock()
+
+	_, exists := vs.availableVoices[name]
+	return exists || len(vs.availableVoices) == 0
+}
+
+// SuggestVoices returns up to limit installed voices that the agent may have meant instead of
+// the requested one: similar names first, then the best voices for the language
+func (vs *VoiceSystem) SuggestVoices(requested, language string, limit int) []string {
+	vs.mu.RLock()
+	defer vs.mu.RUnlock()
+
+	return suggestVoices(vs.voiceList(), requested, language, vs.ranker(), limit)
+}
+
+// StrictVoices reports whether unknown voices are rejected instead of replaced
+func (vs *VoiceSystem) StrictVoices() bool {
+	return vs.strictVoices
+}
+
+// ResolveMissingVoice applies the missing-voice strategy when no installed voice speaks the language.
+// It returns nil if there is such a voice, the catalog is unknown, or the strategy is "none".
+// The fallback language is the default voice's language, or defaultLanguage without one.
+func (vs *VoiceSystem) ResolveMissingVoice(message, language, defaultLanguage string) (*missingVoiceOutcome, error) {
+	if language == "" || vs.missingVoice == "" || vs.missingVoice == missingVoiceNone {
+		return nil, nil
+	}
+
+	vs.mu.RLock()
+	voices := vs.voiceList()
+	fallbackLanguage := defaultLanguage
+	if info, exists := vs.availableVoices[vs.defaultVoice]; exists && info.Language != "" {
+		fallbackLanguage = info.Language
+	}
+	vs.mu.RUnlock()
+
+	base := lexiconLanguage(language)
+	if len(voices) == 0 || base == lexiconLanguage(fallbackLanguage) {
+		return nil, nil
+	}
+	for _, voice := range voices {
+		// Voices without a known language, such as OpenAI-compatible HTTP voices, may speak any language
+		if voice.Language == "" || strings.EqualFold(voice.Language, base) {
+			return nil, nil
 		}
 	}
 
-	vs.lastUpdate = time.Now()
-	debugLog("Loaded %d voices", len(vs.availableVoices))
-	return nil
+	outcome, err := applyMissingVoiceStrategy(vs.missingVoice, message, language, fallbackLanguage)
+	debugLog("No voice for language '%s', applied missing voice strategy: %s", language, outcome.Strategy)
+	return &outcome, err
+}
+
+// FitSpeakOptions clamps the options to the active backend's ranges, returning what was changed
+func (vs *VoiceSystem) FitSpeakOptions(opts SpeakOptions) (SpeakOptions, []prosodyAdjustment) {
+	if vs.backend == nil {
+		return opts, nil
+	}
+	return fitSpeakOptions(opts, vs.backend.Capabilities())
+}
+
+// Persona returns the named persona, or the persona for the priority when name is empty
+func (vs *VoiceSystem) Persona(name, priority string) (Persona, error) {
+	return vs.personas.Resolve(name, priority)
+}
+
+// PersonaNames returns the names of the configured personas
+func (vs *VoiceSystem) PersonaNames() []string {
+	return vs.personas.Names()
+}
+
+// WriteText sends a notification that is not spoken to the text sink
+func (vs *VoiceSystem) WriteText(message, language string) error {
+	return vs.textSink.Write(message, language)
+}
+
+// voiceList returns the available voices; the caller must hold vs.mu
+func (vs *VoiceSystem) voiceList() []VoiceInfo {
+	voices := make([]VoiceInfo, 0, len(vs.availableVoices))
+	for _, info := range vs.availableVoices {
+		voices = append(voices, info)
+	}
+	return voices
+}
+
+// ranker returns the voice ranker for the configured preferences
+func (vs *VoiceSystem) ranker() voiceRanker {
+	return voiceRanker{preferences: vs.preferences, defaultVoice: vs.defaultVoice, systemLocale: vs.systemLocale}
+}
+
+// SelectVoiceForStyle picks the voice for the language that best matches a style hint such as
+// "a calm female voice", and the attributes it matched. It returns no voice if the hint
+// is not understood or no installed voice matches any of it.
+func (vs *VoiceSystem) SelectVoiceForStyle(hint, language string) (string, []string) {
+	style := parseVoiceStyle(hint)
+	if style.IsEmpty() {
+		debugLog("Voice style %q has no recognized attributes", hint)
+		return "", nil
+	}
+
+	vs.mu.RLock()
+	defer vs.mu.RUnlock()
+
+	voices := vs.voiceList()
+	ranked := voices
+	if language != "" {
+		ranked = vs.ranker().Rank(voices, language)
+	} else {
+		sort.Slice(ranked, func(i, j int) bool { return ranked[i].Name < ranked[j].Name })
+	}
+
+	metadata := vs.voiceMetadata
+	if metadata == nil {
+		metadata = builtinVoiceMetadata
+	}
+	backend := vs.BackendName()
+	voice, matched, ok := selectStyledVoice(ranked, style, func(info VoiceInfo) voiceMetadata {
+		found, _ := metadata.Lookup(backend, info.Name)
+		return found
+	})
+	if !ok {
+		debugLog("No %s voice matches style %q", language, hint)
+		return "", nil
+	}
+	debugLogVoiceSelection("style", voice, fmt.Sprintf("matched %s for style: %s", strings.Join(matched, ", "), hint))
+	return voice, matched
 }
 
 // SelectVoice selects the appropriate voice based on preferences
@@ -101,13 +342,11 @@ func (vs *VoiceSystem) SelectVoice(requestedVoice, language string) string {
 		debugLog("Requested voice '%s' not available", requestedVoice)
 	}
 
-	// 2. If language is specified, find a voice for that language
+	// 2. If language is specified, pick the best ranked voice for that language
 	if language != "" {
-		for name, info := range vs.availableVoices {
-			if info.Language == language {
-				debugLogVoiceSelection("language", name, fmt.Sprintf("matched language: %s", language))
-				return name
-			}
+		if ranked := vs.ranker().Rank(vs.voiceList(), language); len(ranked) > 0 {
+			debugLogVoiceSelection("language", ranked[0].Name, fmt.Sprintf("best of %d voices for language: %s", len(ranked), language))
+			return ranked[0].Name
 		}
 		debugLog("No voice found for language '%s'", language)
 	}
@@ -126,48 +365,216 @@ func (vs *VoiceSystem) SelectVoice(requestedVoice, language string) string {
 	return ""
 }
 
-// Speak executes the say command with the given message and voice
-func (vs *VoiceSystem) Speak(message, voice, priority string) error {
-	// Sanitize input to prevent command injection
-	message = sanitizeInput(message)
+// RenderedAudio is a synthesized notification returned instead of being played locally
+type RenderedAudio struct {
+	Data     []byte
+	MIMEType string
+}
 
-	// Build command arguments
-	args := []string{}
+// Speak plays the earcon, if any, and speaks the message, trying the fallback backends
+// in order when the active backend fails. It returns the name of the backend that delivered the message.
+func (vs *VoiceSystem) Speak(ctx context.Context, message, voice string, opts SpeakOptions, earcon string) (string, error) {
+	if vs.backend == nil {
+		return "", errNoSpeechBackend
+	}
 
-	// Add voice if specified
-	if voice != "" {
-		args = append(args, "-v", voice)
+	vs.playEarcon(ctx, earcon)
+	if err := ctx.Err(); err != nil {
+		return "", err
 	}
 
-	// Adjust rate based on priority
-	switch priority {
-	case "high":
-		args = append(args, "-r", "200") // Faster speech
-	case "low":
-		args = append(args, "-r", "150") // Slower speech
-	default:
-		// Normal rate (default)
+	// Sanitize the text but keep the SSML markup, which is translated per backend
+	doc := parseSSML(message).Sanitized()
+
+	chain := append([]SpeechBackend{vs.backend}, vs.fallbacks...)
+	var errs []error
+	for i, backend := range chain {
+		name := backend.Name()
+
+		// The last backend is always tried so the user gets some notification
+		if i < len(chain)-1 && !vs.breaker.Allow(name) {
+			debugLog("Skipping %s backend: circuit open", name)
+			continue
+		}
+
+		// Voice names belong to the active backend; fallbacks use their default voice
+		backendVoice := voice
+		if i > 0 {
+			backendVoice = ""
+		}
+		// The options were fitted to the active backend; fallbacks may support narrower ranges
+		backendOpts, _ := fitSpeakOptions(opts, backend.Capabilities())
+
+		err := vs.speakWithRetry(ctx, backend, doc, backendVoice, backendOpts)
+		if err == nil {
+			vs.breaker.RecordSuccess(name)
+			if i > 0 {
+				log.Printf("Voice notification delivered by fallback backend %s", name)
+			}
+			return name, nil
+		}
+		if ctx.Err() != nil {
+			// Cancelled or stopped; falling back would speak after the user asked for silence
+			return "", err
+		}
+
+		vs.breaker.RecordFailure(name)
+		debugLog("%s backend failed: %v", name, err)
+		errs = append(errs, fmt.Errorf("%s: %w", name, err))
 	}
 
-	// Add the message
-	args = append(args, message)
+	return "", errors.Join(errs...)
+}
+
+// speakWithRetry delivers the message through one backend, retrying transient errors with exponential backoff
+func (vs *VoiceSystem) speakWithRetry(ctx context.Context, backend SpeechBackend, doc ssmlDocument, voice string, opts SpeakOptions) error {
+	backoff := vs.retryBackoff
+	for attempt := 0; ; attempt++ {
+		err := vs.deliver(ctx, backend, doc, voice, opts)
+		if err == nil || attempt >= vs.retries || !isTransientSpeechError(err) {
+			return err
+		}
 
-	// Execute command
-	cmd := exec.Command("say", args...)
+		debugLog("%s backend failed (attempt %d), retrying in %v: %v", backend.Name(), attempt+1, backoff, err)
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		backoff *= 2
+	}
+}
 
-	// Capture both stdout and stderr
-	var stderr bytes.Buffer
-	cmd.Stderr = &stderr
+// deliver speaks the document through a single backend
+func (vs *VoiceSystem) deliver(ctx context.Context, backend SpeechBackend, doc ssmlDocument, voice string, opts SpeakOptions) error {
+	message := speechText(backend, doc, opts)
 
-	debugLogVoiceCommand("say", args, "", nil)
-	err := cmd.Run()
+	// Prefer backends that speak directly, otherwise synthesize and play the file
+	if speaker, ok := backend.(DirectSpeaker); ok {
+		return speaker.Speak(ctx, message, voice, opts)
+	}
+	if synthesizer, ok := backend.(AudioSynthesizer); ok {
+		return vs.synthesizeAndPlay(ctx, synthesizer, message, voice, opts)
+	}
+	return fmt.Errorf("%s backend can neither speak nor synthesize audio", backend.Name())
+}
+
+// playEarcon plays the named earcon through the audio player.
+// Earcons are best effort: when they are disabled or cannot be played, speech goes ahead without them.
+func (vs *VoiceSystem) playEarcon(ctx context.Context, name string) {
+	if name == "" || vs.earcons == nil {
+		return
+	}
+	if vs.player == nil {
+		debugLog("Skipping earcon %s: %v", name, errNoAudioPlayer)
+		return
+	}
+
+	path, cleanup, err := vs.earcons.File(name)
 	if err != nil {
-		errMsg := fmt.Sprintf("say command failed: %v, stderr: %s", err, stderr.String())
-		debugLogVoiceCommand("say", args, "", fmt.Errorf("%s", errMsg))
-		return fmt.Errorf("say command failed: %w, stderr: %s", err, stderr.String())
+		debugLog("Skipping earcon %s: %v", name, err)
+		return
 	}
+	defer cleanup()
 
-	return nil
+	debugLog("Playing earcon %s with %s", name, vs.player.Name())
+	if err := vs.player.Play(ctx, path); err != nil {
+		debugLog("Failed to play earcon %s: %v", name, err)
+	}
+}
+
+// Enqueue queues the message and its earcon for serialized playback and returns
+// the job with the number of notifications ahead of it
+func (vs *VoiceSystem) Enqueue(message, voice string, opts SpeakOptions, earcon string) (*SpeechJob, int) {
+	estimated := estimateSpeechDuration(parseSSML(message).PlainText(), opts.Rate)
+	return vs.speechQueue().Enqueue(message, voice, opts, earcon, estimated)
+}
+
+// CancelJob removes a queued job or stops it if it is being spoken
+func (vs *VoiceSystem) CancelJob(job *SpeechJob) bool {
+	return vs.speechQueue().Cancel(job)
+}
+
+// StopSpeaking stops the current notification and optionally flushes the queue.
+// It reports whether a notification was stopped and how many queued ones were dropped.
+func (vs *VoiceSystem) StopSpeaking(flush bool) (bool, int) {
+	return vs.speechQueue().Stop(flush)
+}
+
+// Close stops any speech in progress and shuts down the speech queue
+func (vs *VoiceSystem) Close() {
+	vs.speechQueue().Close()
+}
+
+// speechQueue returns the speech queue, starting it on first use
+func (vs *VoiceSystem) speechQueue() *speechQueue {
+	vs.queueOnce.Do(func() {
+		vs.queue = newSpeechQueue(vs.Speak)
+	})
+	return vs.queue
+}
+
+// Render synthesizes the message to WAV audio without playing it
+func (vs *VoiceSystem) Render(ctx context.Context, message, voice string, opts SpeakOptions) (*RenderedAudio, error) {
+	if vs.backend == nil {
+		return nil, errNoSpeechBackend
+	}
+
+	synthesizer, ok := vs.backend.(AudioSynthesizer)
+	if !ok {
+		return nil, fmt.Errorf("%s backend cannot render audio files", vs.backend.Name())
+	}
+
+	text := speechText(vs.backend, parseSSML(message).Sanitized(), opts)
+	wavPath, err := vs.synthesizeToTempFile(ctx, synthesizer, text, voice, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(wavPath)
+
+	data, err := os.ReadFile(wavPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rendered audio: %w", err)
+	}
+	if len(data) == 0 {
+		return nil, fmt.Errorf("%s backend produced no audio", vs.backend.Name())
+	}
+
+	debugLog("Rendered %d bytes of audio with %s backend", len(data), vs.backend.Name())
+	return &RenderedAudio{Data: data, MIMEType: "audio/wav"}, nil
+}
+
+// synthesizeAndPlay renders the message to a temporary WAV file and plays it
+func (vs *VoiceSystem) synthesizeAndPlay(ctx context.Context, synthesizer AudioSynthesizer, message, voice string, opts SpeakOptions) error {
+	if vs.player == nil {
+		return errNoAudioPlayer
+	}
+
+	wavPath, err := vs.synthesizeToTempFile(ctx, synthesizer, message, voice, opts)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(wavPath)
+
+	debugLog("Playing synthesized audio with %s", vs.player.Name())
+	return vs.player.Play(ctx, wavPath)
+}
+
+// synthesizeToTempFile renders the message to a new temporary WAV file.
+// The caller is responsible for removing the returned file.
+func (vs *VoiceSystem) synthesizeToTempFile(ctx context.Context, synthesizer AudioSynthesizer, message, voice string, opts SpeakOptions) (string, error) {
+	tmp, err := os.CreateTemp("", "voice-notify-*.wav")
+	if err != nil {
+		return "", fmt.Errorf("failed to create audio file: %w", err)
+	}
+	wavPath := tmp.Name()
+	_ = tmp.Close()
+
+	if err := synthesizer.SynthesizeToFile(ctx, message, voice, opts, wavPath); err != nil {
+		_ = os.Remove(wavPath)
+		return "", err
+	}
+	return wavPath, nil
 }
 
 // GetAvailableVoices returns a list of available voices
@@ -177,9 +584,7 @@ func (vs *VoiceSystem) GetAvailableVoices() []VoiceInfo {
 
 	// Refresh if data is older than 5 minutes
 	if time.Since(vs.lastUpdate) > 5*time.Minute {
-		go func() {
-			_ = vs.refreshVoices() // Background refresh, error is non-critical
-		}()
+		vs.refreshVoicesAsync()
 	}
 
 	voices := make([]VoiceInfo, 0, len(vs.availableVoices))
@@ -190,22 +595,28 @@ func (vs *VoiceSystem) GetAvailableVoices() []VoiceInfo {
 	return voices
 }
 
-// sanitizeInput sanitizes the input to prevent command injection
+// normalizeLocale converts a language tag (e.g., "en-gb" or "en-US") to a locale (e.g., "en_GB")
+func normalizeLocale(tag string) string {
+	parts := strings.SplitN(strings.ReplaceAll(tag, "_", "-"), "-", 2)
+	if len(parts) == 1 {
+		return strings.ToLower(parts[0])
+	}
+	return strings.ToLower(parts[0]) + "_" + strings.ToUpper(parts[1])
+}
+
+// sanitizeInput drops control and format characters, such as terminal escapes and
+// bidirectional overrides, and turns line breaks and tabs into spaces.
+// Text in any script is kept; backends receive it on stdin, not as a command argument.
 func sanitizeInput(input string) string {
-	// Remove any characters that could be used for command injection
-	// Allow only safe characters
 	var sanitized strings.Builder
 
 	for _, r := range input {
-		// Allow alphanumeric, common punctuation, and spaces
-		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') ||
-			(r >= '0' && r <= '9') || r == ' ' || r == '.' ||
-			r == ',' || r == '!' || r == '?' || r == '-' ||
-			r == ':' || r == ';' || r == '(' || r == ')' ||
-			(r >= 0x3040 && r <= 0x309F) || // Hiragana
-			(r >= 0x30A0 && r <= 0x30FF) || // Katakana
-			(r >= 0x4E00 && r <= 0x9FAF) || // CJK Unified Ideographs
-			(r >= 0xAC00 && r <= 0xD7AF) { // Hangul
+		switch {
+		case unicode.IsSpace(r):
+			sanitized.WriteRune(' ')
+		case unicode.In(r, unicode.Cc, unicode.Cf):
+			continue
+		default:
 			sanitized.WriteRune(r)
 		}
 	}
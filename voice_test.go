@@ -53,7 +53,7 @@ func TestVoiceSystem_SelectVoice(t *testing.T) {
 			name:           "specific_voice_unavailable",
 			requestedVoice: "Unknown",
 			language:       "en",
-			expected:       "Alex", // Could be any English voice (Alex or Samantha)
+			expected:       "Samantha", // The default voice ranks first among English voices
 			description:    "Should fall back to language match when requested voice unavailable",
 		},
 		{
@@ -97,13 +97,7 @@ func TestVoiceSystem_SelectVoice(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			result := vs.SelectVoice(tt.requestedVoice, tt.language)
 
-			// Special case for language match tests where multiple voices are acceptable
-			if tt.name == "specific_voice_unavailable" {
-				// Accept any English voice
-				if result != "Alex" && result != "Samantha" {
-					t.Errorf("%s: expected Alex or Samantha, got %s", tt.description, result)
-				}
-			} else if result != tt.expected {
+			if result != tt.expected {
 				t.Errorf("%s: expected %s, got %s", tt.description, tt.expected, result)
 			}
 		})
@@ -157,39 +151,44 @@ func TestSanitizeInput(t *testing.T) {
 			expected: "Hello world",
 		},
 		{
-			name:     "with_quotes",
-			input:    `Hello "world"`,
-			expected: `Hello world`, // quotes are removed
+			name:     "shell_characters",
+			input:    `"; say "hacked"; # $USER \ ` + "`id`",
+			expected: `"; say "hacked"; # $USER \ ` + "`id`", // harmless, the text never reaches a shell
 		},
 		{
-			name:     "with_backticks",
-			input:    "Hello `world`",
-			expected: "Hello world", // backticks are removed
+			name:     "leading_dash",
+			input:    "-v Alex",
+			expected: "-v Alex", // passed on stdin, so not an option
 		},
 		{
-			name:     "with_dollar_signs",
-			input:    "Hello $USER",
-			expected: "Hello USER", // dollar sign removed
+			name:     "unicode_text",
+			input:    "こんにちは世界",
+			expected: "こんにちは世界", // Japanese preserved
 		},
 		{
-			name:     "with_backslash",
-			input:    `Hello\nworld`,
-			expected: `Hellonworld`, // backslash removed
+			name:     "accented_latin",
+			input:    "Tâche terminée",
+			expected: "Tâche terminée",
 		},
 		{
-			name:     "complex_injection_attempt",
-			input:    `"; say "hacked"; #`,
-			expected: `; say hacked; `, // quotes and # removed
+			name:     "other_scripts",
+			input:    "Задача выполнена, המשימה הושלמה, اكتملت المهمة, Η εργασία ολοκληρώθηκε",
+			expected: "Задача выполнена, המשימה הושלמה, اكتملت المهمة, Η εργασία ολοκληρώθηκε",
 		},
 		{
-			name:     "unicode_text",
-			input:    "こんにちは世界",
-			expected: "こんにちは世界", // Japanese preserved
+			name:     "line_breaks",
+			input:    "Build done\nTests\tpassed",
+			expected: "Build done Tests passed",
+		},
+		{
+			name:     "control_characters",
+			input:    "Build \x1b[31mfailed\x07\x00",
+			expected: "Build [31mfailed", // terminal escape and bell dropped
 		},
 		{
-			name:     "mixed_special_chars",
-			input:    `Test $VAR with "quotes" and \backslash`,
-			expected: `Test VAR with quotes and backslash`, // special chars removed
+			name:     "format_characters",
+			input:    "safe\u202Etxt.exe\u200B done",
+			expected: "safetxt.exe done", // bidi override and zero-width space dropped
 		},
 		{
 			name:     "allowed_punctuation",
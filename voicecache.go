@@ -0,0 +1,170 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// defaultVoiceCacheTTL is how long a cached voice catalog is used at startup
+const defaultVoiceCacheTTL = 24 * time.Hour
+
+// firstRefreshTimeout bounds how long startup waits for the voice list when there is no usable cache
+const firstRefreshTimeout = 5 * time.Second
+
+// catalogFingerprinter is implemented by backends whose voice catalog can be cached on disk.
+// The fingerprint changes when the catalog may have changed, e.g. when the backend binary is upgraded.
+type catalogFingerprinter interface {
+	CatalogFingerprint() string
+}
+
+// voiceCatalogCache stores a backend's parsed voice catalog under the user cache directory,
+// so the server knows its voices as soon as it starts
+type voiceCatalogCache struct {
+	path        string
+	backend     string
+	fingerprint string
+	ttl         time.Duration
+	now         func() time.Time // For tests; nil means time.Now
+}
+
+// voiceCatalogFile is the on-disk format of the cache
+type voiceCatalogFile struct {
+	Backend     string      `json:"backend"`
+	Fingerprint string      `json:"fingerprint"`
+	Updated     time.Time   `json:"updated"`
+	Voices      []VoiceInfo `json:"voices"`
+}
+
+// newVoiceCatalogCache creates the cache for a backend, configured by VOICE_NOTIFY_VOICE_CACHE_TTL
+// (hours, 0 disables). It returns nil if the backend's catalog cannot be cached.
+func newVoiceCatalogCache(backend SpeechBackend) *voiceCatalogCache {
+	ttl := defaultVoiceCacheTTL
+	if value := getEnv("VOICE_NOTIFY_VOICE_CACHE_TTL", ""); value != "" {
+		hours, err := strconv.Atoi(value)
+		if err != nil || hours < 0 {
+			log.Printf("Invalid VOICE_NOTIFY_VOICE_CACHE_TTL, using %d", int(defaultVoiceCacheTTL.Hours()))
+		} else {
+			ttl = time.Duration(hours) * time.Hour
+		}
+	}
+	if ttl == 0 {
+		return nil
+	}
+
+	fingerprinter, ok := backend.(catalogFingerprinter)
+	if !ok {
+		return nil
+	}
+	fingerprint := fingerprinter.CatalogFingerprint()
+	if fingerprint == "" {
+		return nil
+	}
+
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		debugLog("Voice catalog cache disabled: %v", err)
+		return nil
+	}
+
+	return &voiceCatalogCache{
+		path:        filepath.Join(cacheDir, "voice-notify-mcp", "voices-"+backend.Name()+".json"),
+		backend:     backend.Name(),
+		fingerprint: fingerprint,
+		ttl:         ttl,
+	}
+}
+
+// commandFingerprint identifies the installed version of a command by its resolved path, size and
+// modification time, so upgrading or replacing the binary invalidates the cache
+func commandFingerprint(command string) string {
+	path, err := exec.LookPath(command)
+	if err != nil {
+		return ""
+	}
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf("%s:%d:%d", path, info.Size(), info.ModTime().UnixNano())
+}
+
+// Load returns the cached voices and when they were listed, if the cache is fresh and
+// belongs to the same backend binary
+func (c *voiceCatalogCache) Load() ([]VoiceInfo, time.Time, bool) {
+	if c == nil {
+		return nil, time.Time{}, false
+	}
+
+	data, err := os.ReadFile(c.path)
+	if err != nil {
+		return nil, time.Time{}, false
+	}
+	var file voiceCatalogFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		debugLog("Ignoring invalid voice catalog cache %s: %v", c.path, err)
+		return nil, time.Time{}, false
+	}
+
+	if file.Backend != c.backend || file.Fingerprint != c.fingerprint {
+		debugLog("Voice catalog cache is for another %s binary, ignoring it", c.backend)
+		return nil, time.Time{}, false
+	}
+	if c.currentTime().Sub(file.Updated) > c.ttl {
+		debugLog("Voice catalog cache expired (updated %s)", file.Updated.Format(time.RFC3339))
+		return nil, time.Time{}, false
+	}
+
+	return file.Voices, file.Updated, true
+}
+
+// Save writes the voices to the cache, replacing the file atomically
+func (c *voiceCatalogCache) Save(voices []VoiceInfo) error {
+	if c == nil {
+		return nil
+	}
+
+	data, err := json.Marshal(voiceCatalogFile{
+		Backend:     c.backend,
+		Fingerprint: c.fingerprint,
+		Updated:     c.currentTime(),
+		Voices:      voices,
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(c.path), ".voices-*.json")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), c.path)
+}
+
+// currentTime returns the cache's clock reading
+func (c *voiceCatalogCache) currentTime() time.Time {
+	if c.now != nil {
+		return c.now()
+	}
+	return time.Now()
+}
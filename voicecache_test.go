@@ -0,0 +1,193 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestVoiceCatalogCache_SaveLoad tests round-tripping the catalog and the reasons to ignore it
+func TestVoiceCatalogCache_SaveLoad(t *testing.T) {
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	path := filepath.Join(t.TempDir(), "voice-notify-mcp", "voices-say.json")
+	cache := &voiceCatalogCache{path: path, backend: "say", fingerprint: "/usr/bin/say:1:1", ttl: time.Hour, now: func() time.Time { return now }}
+
+	if _, _, ok := cache.Load(); ok {
+		t.Fatal("Load() before Save() reported a cached catalog")
+	}
+
+	voices := []VoiceInfo{{Name: "Kyoko (Enhanced)", Language: "ja", Locale: "ja_JP", Sample: "こんにちは", Quality: voiceQualityEnhanced}}
+	if err := cache.Save(voices); err != nil {
+		t.Fatalf("Save() unexpected error: %v", err)
+	}
+	got, updated, ok := cache.Load()
+	if !ok || !reflect.DeepEqual(got, voices) || !updated.Equal(now) {
+		t.Errorf("Load() = %+v, %v, %v, want the saved voices", got, updated, ok)
+	}
+
+	upgraded := *cache
+	upgraded.fingerprint = "/usr/bin/say:2:2"
+	if _, _, ok := upgraded.Load(); ok {
+		t.Error("Load() with another binary fingerprint used the cache")
+	}
+
+	other := *cache
+	other.backend = "espeak-ng"
+	if _, _, ok := other.Load(); ok {
+		t.Error("Load() for another backend used the cache")
+	}
+
+	later := *cache
+	later.now = func() time.Time { return now.Add(2 * time.Hour) }
+	if _, _, ok := later.Load(); ok {
+		t.Error("Load() of an expired cache used it")
+	}
+
+	var disabled *voiceCatalogCache
+	if err := disabled.Save(voices); err != nil {
+		t.Errorf("nil cache Save() error = %v", err)
+	}
+	if _, _, ok := disabled.Load(); ok {
+		t.Error("nil cache Load() reported a cached catalog")
+	}
+}
+
+// TestCommandFingerprint tests that replacing a binary changes its fingerprint
+func TestCommandFingerprint(t *testing.T) {
+	installStubCommand(t, "espeak-ng", "exit 0")
+	before := commandFingerprint("espeak-ng")
+	if before == "" {
+		t.Fatal("commandFingerprint() of an installed command is empty")
+	}
+
+	path := filepath.Join(filepath.SplitList(os.Getenv("PATH"))[0], "espeak-ng")
+	if err := os.WriteFile(path, []byte("#!/bin/sh\necho upgraded\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if after := commandFingerprint("espeak-ng"); after == before {
+		t.Errorf("commandFingerprint() = %q after replacing the binary, want a new fingerprint", after)
+	}
+
+	if got := commandFingerprint("voice-notify-missing-command"); got != "" {
+		t.Errorf("commandFingerprint() of a missing command = %q, want empty", got)
+	}
+}
+
+// TestNewVoiceCatalogCache tests which backends get a cache and where it is stored
+func TestNewVoiceCatalogCache(t *testing.T) {
+	cacheDir := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", cacheDir)
+	t.Setenv("HOME", cacheDir)
+	installStubCommand(t, "espeak-ng", "exit 0")
+
+	cache := newVoiceCatalogCache(newEspeakBackend())
+	if cache == nil {
+		t.Fatal("newVoiceCatalogCache() = nil for espeak-ng")
+	}
+	if userCacheDir, _ := os.UserCacheDir(); cache.path != filepath.Join(userCacheDir, "voice-notify-mcp", "voices-espeak-ng.json") || cache.ttl != defaultVoiceCacheTTL {
+		t.Errorf("cache = %+v, want the user cache dir and default TTL", cache)
+	}
+
+	if cache := newVoiceCatalogCache(&fakeBackend{}); cache != nil {
+		t.Errorf("newVoiceCatalogCache() = %+v for a backend without a fingerprint, want nil", cache)
+	}
+
+	t.Setenv("VOICE_NOTIFY_VOICE_CACHE_TTL", "0")
+	if cache := newVoiceCatalogCache(newEspeakBackend()); cache != nil {
+		t.Errorf("newVoiceCatalogCache() = %+v with TTL 0, want nil", cache)
+	}
+}
+
+// countingBackend lists its voices after release is closed, counting the calls
+type countingBackend struct {
+	fakeBackend
+	calls   atomic.Int32
+	release chan struct{}
+}
+
+func (b *countingBackend) ListVoices(ctx context.Context) ([]VoiceInfo, error) {
+	b.calls.Add(1)
+	<-b.release
+	return b.voices, nil
+}
+
+// TestVoiceSystem_RefreshVoicesAsync tests that background refreshes never overlap and update the cache
+func TestVoiceSystem_RefreshVoicesAsync(t *testing.T) {
+	backend := &countingBackend{
+		fakeBackend: fakeBackend{voices: []VoiceInfo{{Name: "Kyoko", Language: "ja", Locale: "ja_JP"}}},
+		release:     make(chan struct{}),
+	}
+	cache := &voiceCatalogCache{path: filepath.Join(t.TempDir(), "voices-fake.json"), backend: "fake", fingerprint: "v1", ttl: time.Hour}
+	vs := &VoiceSystem{backend: backend, availableVoices: make(map[string]VoiceInfo), catalogCache: cache}
+
+	for range 5 {
+		vs.refreshVoicesAsync()
+		vs.GetAvailableVoices()
+	}
+	close(backend.release)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for vs.refreshing.Load() && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if calls := backend.calls.Load(); calls != 1 {
+		t.Errorf("ListVoices() called %d times, want 1", calls)
+	}
+	if got := vs.SelectVoice("", "ja"); got != "Kyoko" {
+		t.Errorf("SelectVoice() after refresh = %q, want %q", got, "Kyoko")
+	}
+
+	// A new voice system starts with the cached catalog
+	restarted := &VoiceSystem{backend: &fakeBackend{}, availableVoices: make(map[string]VoiceInfo), catalogCache: cache}
+	restarted.loadCachedVoices()
+	if got := restarted.SelectVoice("", "ja"); got != "Kyoko" {
+		t.Errorf("SelectVoice() from cache = %q, want %q", got, "Kyoko")
+	}
+}
+
+// TestVoiceSystem_LoadVoices tests waiting for the first listing only when there is no cache
+func TestVoiceSystem_LoadVoices(t *testing.T) {
+	voices := []VoiceInfo{{Name: "Kyoko", Language: "ja", Locale: "ja_JP"}}
+	cache := &voiceCatalogCache{path: filepath.Join(t.TempDir(), "voices-fake.json"), backend: "fake", fingerprint: "v1", ttl: time.Hour}
+
+	// Without a cache the first listing is awaited
+	vs := &VoiceSystem{backend: &fakeBackend{voices: voices}, availableVoices: make(map[string]VoiceInfo), catalogCache: cache}
+	vs.loadVoices(time.Second)
+	if got := vs.SelectVoice("", "ja"); got != "Kyoko" {
+		t.Errorf("SelectVoice() after loadVoices() = %q, want %q", got, "Kyoko")
+	}
+
+	// A listing that hangs only delays startup until the timeout
+	stuck := &countingBackend{fakeBackend: fakeBackend{voices: voices}, release: make(chan struct{})}
+	empty := &voiceCatalogCache{path: filepath.Join(t.TempDir(), "voices-stuck.json"), backend: "fake", fingerprint: "v1", ttl: time.Hour}
+	stuckSystem := &VoiceSystem{backend: stuck, availableVoices: make(map[string]VoiceInfo), catalogCache: empty}
+	start := time.Now()
+	stuckSystem.loadVoices(50 * time.Millisecond)
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("loadVoices() took %v, want it bounded by the timeout", elapsed)
+	}
+
+	// With a fresh cache startup does not wait for the refresh
+	cached := &countingBackend{fakeBackend: fakeBackend{voices: voices}, release: make(chan struct{})}
+	cachedSystem := &VoiceSystem{backend: cached, availableVoices: make(map[string]VoiceInfo), catalogCache: cache}
+	start = time.Now()
+	cachedSystem.loadVoices(time.Minute)
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("loadVoices() with a cache took %v, want no wait", elapsed)
+	}
+	if got := cachedSystem.SelectVoice("", "ja"); got != "Kyoko" {
+		t.Errorf("SelectVoice() from cache = %q, want %q", got, "Kyoko")
+	}
+
+	// Let the background refreshes finish before the temporary directories are removed
+	close(stuck.release)
+	close(cached.release)
+	deadline := time.Now().Add(2 * time.Second)
+	for (stuckSystem.refreshing.Load() || cachedSystem.refreshing.Load()) && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+}
@@ -0,0 +1,351 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// voiceMetadataAnyBackend is the metadata key for voices of every backend, and the
+// voice key for the defaults of a backend
+const voiceMetadataAnyBackend = "*"
+
+// Voice genders and age groups
+const (
+	genderFemale = "female"
+	genderMale   = "male"
+	ageChild     = "child"
+	ageYoung     = "young"
+	ageAdult     = "adult"
+	ageSenior    = "senior"
+	styleNovelty = "novelty" // Sound effects and character voices, only chosen when asked for
+)
+
+// voiceMetadata describes what a voice sounds like
+type voiceMetadata struct {
+	Gender  string   `json:"gender,omitempty"`
+	Age     string   `json:"age,omitempty"`
+	Styles  []string `json:"styles,omitempty"`  // e.g. "calm", "warm", "serious"
+	Quality string   `json:"quality,omitempty"` // Quality tier, for backends that do not report one
+}
+
+// builtinVoiceMetadata describes well-known voices, by backend then voice name.
+// macOS quality variants share an entry ("Kyoko (Enhanced)" uses "Kyoko"), piper voices are keyed
+// by locale and speaker ("en_US-lessac" covers every quality), and "*" holds a backend's defaults.
+var builtinVoiceMetadata = map[string]map[string]voiceMetadata{
+	"say": {
+		"Samantha":  {Gender: genderFemale, Age: ageAdult, Styles: []string{"neutral", "friendly"}},
+		"Alex":      {Gender: genderMale, Age: ageAdult, Styles: []string{"neutral", "calm"}},
+		"Allison":   {Gender: genderFemale, Age: ageAdult, Styles: []string{"calm", "warm"}},
+		"Ava":       {Gender: genderFemale, Age: ageAdult, Styles: []string{"warm", "friendly"}},
+		"Susan":     {Gender: genderFemale, Age: ageAdult, Styles: []string{"neutral"}},
+		"Tom":       {Gender: genderMale, Age: ageAdult, Styles: []string{"neutral"}},
+		"Evan":      {Gender: genderMale, Age: ageAdult, Styles: []string{"calm"}},
+		"Nathan":    {Gender: genderMale, Age: ageAdult, Styles: []string{"friendly"}},
+		"Zoe":       {Gender: genderFemale, Age: ageAdult, Styles: []string{"warm", "calm"}},
+		"Daniel":    {Gender: genderMale, Age: ageAdult, Styles: []string{"calm", "serious"}},
+		"Jamie":     {Gender: genderMale, Age: ageYoung, Styles: []string{"friendly"}},
+		"Serena":    {Gender: genderFemale, Age: ageAdult, Styles: []string{"calm", "serious"}},
+		"Karen":     {Gender: genderFemale, Age: ageAdult, Styles: []string{"friendly", "cheerful"}},
+		"Lee":       {Gender: genderMale, Age: ageAdult, Styles: []string{"calm"}},
+		"Moira":     {Gender: genderFemale, Age: ageAdult, Styles: []string{"warm"}},
+		"Tessa":     {Gender: genderFemale, Age: ageAdult, Styles: []string{"friendly"}},
+		"Rishi":     {Gender: genderMale, Age: ageAdult, Styles: []string{"neutral"}},
+		"Veena":     {Gender: genderFemale, Age: ageAdult, Styles: []string{"neutral"}},
+		"Victoria":  {Gender: genderFemale, Age: ageAdult, Styles: []string{"serious"}},
+		"Fred":      {Gender: genderMale, Age: ageAdult, Styles: []string{"robotic"}},
+		"Kathy":     {Gender: genderFemale, Age: ageAdult, Styles: []string{"robotic"}},
+		"Ralph":     {Gender: genderMale, Age: ageAdult, Styles: []string{"robotic"}},
+		"Junior":    {Gender: genderMale, Age: ageChild, Styles: []string{"robotic"}},
+		"Eddy":      {Gender: genderMale, Age: ageYoung, Styles: []string{"cheerful"}},
+		"Flo":       {Gender: genderFemale, Age: ageYoung, Styles: []string{"cheerful"}},
+		"Reed":      {Gender: genderMale, Age: ageAdult, Styles: []string{"calm"}},
+		"Rocko":     {Gender: genderMale, Age: ageAdult, Styles: []string{"serious"}},
+		"Sandy":     {Gender: genderFemale, Age: ageAdult, Styles: []string{"friendly"}},
+		"Shelley":   {Gender: genderFemale, Age: ageAdult, Styles: []string{"warm"}},
+		"Grandma":   {Gender: genderFemale, Age: ageSenior, Styles: []string{"warm", "calm"}},
+		"Grandpa":   {Gender: genderMale, Age: ageSenior, Styles: []string{"warm", "calm"}},
+		"Kyoko":     {Gender: genderFemale, Age: ageAdult, Styles: []string{"calm", "friendly"}},
+		"Otoya":     {Gender: genderMale, Age: ageAdult, Styles: []string{"calm"}},
+		"Hattori":   {Gender: genderMale, Age: ageAdult, Styles: []string{"serious"}},
+		"O-Ren":     {Gender: genderFemale, Age: ageAdult, Styles: []string{"warm"}},
+		"Thomas":    {Gender: genderMale, Age: ageAdult, Styles: []string{"calm"}},
+		"Amélie":    {Gender: genderFemale, Age: ageAdult, Styles: []string{"friendly"}},
+		"Audrey":    {Gender: genderFemale, Age: ageAdult, Styles: []string{"warm"}},
+		"Anna":      {Gender: genderFemale, Age: ageAdult, Styles: []string{"neutral"}},
+		"Markus":    {Gender: genderMale, Age: ageAdult, Styles: []string{"serious"}},
+		"Mónica":    {Gender: genderFemale, Age: ageAdult, Styles: []string{"cheerful"}},
+		"Jorge":     {Gender: genderMale, Age: ageAdult, Styles: []string{"neutral"}},
+		"Paulina":   {Gender: genderFemale, Age: ageAdult, Styles: []string{"friendly"}},
+		"Alice":     {Gender: genderFemale, Age: ageAdult, Styles: []string{"friendly"}},
+		"Luca":      {Gender: genderMale, Age: ageAdult, Styles: []string{"neutral"}},
+		"Luciana":   {Gender: genderFemale, Age: ageAdult, Styles: []string{"friendly"}},
+		"Joana":     {Gender: genderFemale, Age: ageAdult, Styles: []string{"neutral"}},
+		"Milena":    {Gender: genderFemale, Age: ageAdult, Styles: []string{"calm"}},
+		"Yuri":      {Gender: genderMale, Age: ageAdult, Styles: []string{"serious"}},
+		"Yuna":      {Gender: genderFemale, Age: ageYoung, Styles: []string{"cheerful", "friendly"}},
+		"Tingting":  {Gender: genderFemale, Age: ageAdult, Styles: []string{"neutral"}},
+		"Meijia":    {Gender: genderFemale, Age: ageAdult, Styles: []string{"friendly"}},
+		"Sinji":     {Gender: genderFemale, Age: ageAdult, Styles: []string{"neutral"}},
+		"Carmit":    {Gender: genderFemale, Age: ageAdult, Styles: []string{"neutral"}},
+		"Majed":     {Gender: genderMale, Age: ageAdult, Styles: []string{"neutral"}},
+		"Alva":      {Gender: genderFemale, Age: ageAdult, Styles: []string{"calm"}},
+		"Damayanti": {Gender: genderFemale, Age: ageAdult, Styles: []string{"neutral"}},
+		"Amira":     {Gender: genderFemale, Age: ageAdult, Styles: []string{"neutral"}},
+		"Albert":    {Gender: genderMale, Age: ageSenior, Styles: []string{styleNovelty}},
+		"Bad News":  {Styles: []string{styleNovelty, "serious"}},
+		"Good News": {Styles: []string{styleNovelty, "cheerful"}},
+		"Bahh":      {Styles: []string{styleNovelty}},
+		"Bells":     {Styles: []string{styleNovelty}},
+		"Boing":     {Styles: []string{styleNovelty}},
+		"Bubbles":   {Styles: []string{styleNovelty}},
+		"Cellos":    {Styles: []string{styleNovelty}},
+		"Jester":    {Gender: genderMale, Styles: []string{styleNovelty}},
+		"Organ":     {Styles: []string{styleNovelty}},
+		"Superstar": {Styles: []string{styleNovelty}},
+		"Trinoids":  {Styles: []string{styleNovelty, "robotic"}},
+		"Whisper":   {Gender: genderMale, Styles: []string{styleNovelty, "whisper"}},
+		"Wobble":    {Styles: []string{styleNovelty}},
+		"Zarvox":    {Styles: []string{styleNovelty, "robotic"}},
+	},
+	// espeak-ng voices are formant-synthesized and male unless a variant is chosen
+	"espeak-ng": {
+		voiceMetadataAnyBackend: {Gender: genderMale, Age: ageAdult, Styles: []string{"robotic"}, Quality: voiceQualityDefault},
+	},
+	"piper": {
+		"en_US-lessac":                {Gender: genderFemale, Age: ageAdult, Styles: []string{"neutral", "calm"}},
+		"en_US-amy":                   {Gender: genderFemale, Age: ageAdult, Styles: []string{"friendly"}},
+		"en_US-kristin":               {Gender: genderFemale, Age: ageAdult, Styles: []string{"warm"}},
+		"en_US-kathleen":              {Gender: genderFemale, Age: ageAdult, Styles: []string{"calm"}},
+		"en_US-hfc_female":            {Gender: genderFemale, Age: ageAdult, Styles: []string{"neutral"}},
+		"en_US-ryan":                  {Gender: genderMale, Age: ageAdult, Styles: []string{"neutral"}},
+		"en_US-joe":                   {Gender: genderMale, Age: ageAdult, Styles: []string{"friendly"}},
+		"en_US-john":                  {Gender: genderMale, Age: ageAdult, Styles: []string{"serious"}},
+		"en_US-danny":                 {Gender: genderMale, Age: ageYoung, Styles: []string{"cheerful"}},
+		"en_US-hfc_male":              {Gender: genderMale, Age: ageAdult, Styles: []string{"neutral"}},
+		"en_GB-alan":                  {Gender: genderMale, Age: ageAdult, Styles: []string{"calm"}},
+		"en_GB-northern_english_male": {Gender: genderMale, Age: ageAdult, Styles: []string{"warm"}},
+		"en_GB-alba":                  {Gender: genderFemale, Age: ageAdult, Styles: []string{"neutral"}},
+		"en_GB-jenny_dioco":           {Gender: genderFemale, Age: ageAdult, Styles: []string{"friendly"}},
+		"en_GB-cori":                  {Gender: genderFemale, Age: ageAdult, Styles: []string{"calm"}},
+		"de_DE-thorsten":              {Gender: genderMale, Age: ageAdult, Styles: []string{"neutral"}},
+		"de_DE-eva_k":                 {Gender: genderFemale, Age: ageAdult, Styles: []string{"neutral"}},
+		"fr_FR-siwis":                 {Gender: genderFemale, Age: ageAdult, Styles: []string{"neutral"}},
+		"fr_FR-tom":                   {Gender: genderMale, Age: ageAdult, Styles: []string{"neutral"}},
+		"fr_FR-gilles":                {Gender: genderMale, Age: ageAdult, Styles: []string{"calm"}},
+		"es_ES-davefx":                {Gender: genderMale, Age: ageAdult, Styles: []string{"neutral"}},
+		"ru_RU-irina":                 {Gender: genderFemale, Age: ageAdult, Styles: []string{"neutral"}},
+		"ru_RU-dmitri":                {Gender: genderMale, Age: ageAdult, Styles: []string{"neutral"}},
+		"ru_RU-denis":                 {Gender: genderMale, Age: ageAdult, Styles: []string{"neutral"}},
+	},
+}
+
+// voiceStyleWords maps words of a style hint to the attribute they ask for
+var voiceStyleWords = map[string]struct{ field, value string }{
+	"female": {"gender", genderFemale}, "woman": {"gender", genderFemale}, "feminine": {"gender", genderFemale},
+	"girl": {"gender", genderFemale}, "lady": {"gender", genderFemale},
+	"male": {"gender", genderMale}, "man": {"gender", genderMale}, "masculine": {"gender", genderMale},
+	"boy": {"gender", genderMale}, "guy": {"gender", genderMale},
+	"child": {"age", ageChild}, "kid": {"age", ageChild}, "childlike": {"age", ageChild},
+	"young": {"age", ageYoung}, "youthful": {"age", ageYoung},
+	"adult": {"age", ageAdult}, "mature": {"age", ageAdult},
+	"old": {"age", ageSenior}, "older": {"age", ageSenior}, "elderly": {"age", ageSenior}, "senior": {"age", ageSenior},
+	"calm": {"style", "calm"}, "soothing": {"style", "calm"}, "relaxed": {"style", "calm"}, "gentle": {"style", "calm"}, "soft": {"style", "calm"},
+	"warm": {"style", "warm"}, "kind": {"style", "warm"},
+	"friendly": {"style", "friendly"},
+	"cheerful": {"style", "cheerful"}, "happy": {"style", "cheerful"}, "upbeat": {"style", "cheerful"},
+	"energetic": {"style", "cheerful"}, "bright": {"style", "cheerful"},
+	"serious": {"style", "serious"}, "formal": {"style", "serious"}, "professional": {"style", "serious"}, "authoritative": {"style", "serious"},
+	"neutral": {"style", "neutral"}, "plain": {"style", "neutral"},
+	"robotic": {"style", "robotic"}, "robot": {"style", "robotic"}, "synthetic": {"style", "robotic"}, "mechanical": {"style", "robotic"},
+	"fun": {"style", styleNovelty}, "funny": {"style", styleNovelty}, "silly": {"style", styleNovelty}, "novelty": {"style", styleNovelty},
+	"whisper": {"style", "whisper"}, "whispering": {"style", "whisper"},
+	"natural": {"quality", ""}, "realistic": {"quality", ""}, "premium": {"quality", ""}, "human": {"quality", ""},
+}
+
+var (
+	styleWordPattern  = regexp.MustCompile(`\p{L}+`)
+	piperQualityTiers = map[string]string{"x_low": voiceQualityDefault, "low": voiceQualityDefault, "medium": voiceQualityDefault, "high": voiceQualityEnhanced}
+)
+
+// voiceStyle is what a style hint such as "a calm female voice" asks for
+type voiceStyle struct {
+	Gender  string
+	Age     string
+	Styles  []string
+	Quality bool // Prefer natural-sounding voices
+}
+
+// voiceMetadataTable holds voice metadata by backend then voice name
+type voiceMetadataTable map[string]map[string]voiceMetadata
+
+// loadVoiceMetadata returns the built-in metadata extended by the file in VOICE_NOTIFY_VOICE_METADATA
+func loadVoiceMetadata() voiceMetadataTable {
+	path := getEnv("VOICE_NOTIFY_VOICE_METADATA", "")
+	if path == "" {
+		return builtinVoiceMetadata
+	}
+
+	custom, err := loadVoiceMetadataFile(path)
+	if err != nil {
+		log.Printf("Failed to load VOICE_NOTIFY_VOICE_METADATA: %v", err)
+		return builtinVoiceMetadata
+	}
+	debugLog("Loaded voice metadata from %s", path)
+	return mergeVoiceMetadata(builtinVoiceMetadata, custom)
+}
+
+// loadVoiceMetadataFile reads a JSON voice metadata file
+// Format: {"say": {"Ava": {"gender": "female", "styles": ["warm"]}}, "*": {"my-voice": {"gender": "male"}}}
+func loadVoiceMetadataFile(path string) (voiceMetadataTable, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var table voiceMetadataTable
+	if err := json.Unmarshal(data, &table); err != nil {
+		return nil, fmt.Errorf("invalid voice metadata file %s: %w", path, err)
+	}
+	return table, nil
+}
+
+// mergeVoiceMetadata overlays custom entries on the base table; a custom entry replaces a built-in one
+func mergeVoiceMetadata(base, custom voiceMetadataTable) voiceMetadataTable {
+	merged := make(voiceMetadataTable)
+	for _, source := range []voiceMetadataTable{base, custom} {
+		for backend, voices := range source {
+			if merged[backend] == nil {
+				merged[backend] = make(map[string]voiceMetadata)
+			}
+			for name, metadata := range voices {
+				merged[backend][name] = metadata
+			}
+		}
+	}
+	return merged
+}
+
+// Lookup returns the metadata of a backend's voice, trying the exact name, then the name
+// without a quality variant or speaker, then the backend's defaults.
+// Entries for any backend ("*") come after the backend's own.
+func (t voiceMetadataTable) Lookup(backend, name string) (voiceMetadata, bool) {
+	keys := []string{name}
+	if base, _, found := strings.Cut(name, " ("); found {
+		keys = append(keys, base)
+	}
+	if model, _, found := strings.Cut(name, ":"); found {
+		keys = append(keys, model)
+	}
+
+	// Piper models are named locale-speaker-quality, e.g. "en_US-lessac-medium"
+	quality := ""
+	for _, key := range keys {
+		if i := strings.LastIndex(key, "-"); i > 0 {
+			if tier, ok := piperQualityTiers[key[i+1:]]; ok {
+				keys = append(keys, key[:i])
+				quality = tier
+				break
+			}
+		}
+	}
+
+	for _, table := range []map[string]voiceMetadata{t[backend], t[voiceMetadataAnyBackend]} {
+		for _, key := range append(keys, voiceMetadataAnyBackend) {
+			if metadata, ok := table[key]; ok {
+				if metadata.Quality == "" {
+					metadata.Quality = quality
+				}
+				return metadata, true
+			}
+		}
+	}
+	return voiceMetadata{Quality: quality}, false
+}
+
+// parseVoiceStyle reads the attributes a free-form hint asks for; unknown words are ignored
+func parseVoiceStyle(hint string) voiceStyle {
+	var style voiceStyle
+	for _, word := range styleWordPattern.FindAllString(strings.ToLower(hint), -1) {
+		attribute, ok := voiceStyleWords[word]
+		if !ok {
+			continue
+		}
+		switch attribute.field {
+		case "gender":
+			style.Gender = attribute.value
+		case "age":
+			style.Age = attribute.value
+		case "style":
+			if !slices.Contains(style.Styles, attribute.value) {
+				style.Styles = append(style.Styles, attribute.value)
+			}
+		case "quality":
+			style.Quality = true
+		}
+	}
+	return style
+}
+
+// IsEmpty reports whether the hint asked for nothing recognizable
+func (s voiceStyle) IsEmpty() bool {
+	return s.Gender == "" && s.Age == "" && len(s.Styles) == 0 && !s.Quality
+}
+
+// match scores a voice against the style and lists the attributes it matches.
+// A wrong gender counts against the voice, and novelty voices are only chosen when asked for.
+func (s voiceStyle) match(metadata voiceMetadata) (int, []string) {
+	score := 0
+	var matched []string
+	if s.Gender != "" && metadata.Gender != "" {
+		if metadata.Gender == s.Gender {
+			score += 3
+			matched = append(matched, s.Gender)
+		} else {
+			score -= 3
+		}
+	}
+	if s.Age != "" && metadata.Age == s.Age {
+		score += 2
+		matched = append(matched, s.Age)
+	}
+	for _, style := range s.Styles {
+		if slices.Contains(metadata.Styles, style) {
+			score += 2
+			matched = append(matched, style)
+		}
+	}
+	if s.Quality && voiceQualityRanks[metadata.Quality] > 0 {
+		score++
+		matched = append(matched, metadata.Quality)
+	}
+	if slices.Contains(metadata.Styles, styleNovelty) && !slices.Contains(s.Styles, styleNovelty) {
+		score -= 5
+	}
+	return score, matched
+}
+
+// selectStyledVoice picks the ranked voice that best matches the style, keeping the ranker's
+// order among equal matches. It reports false if no voice matches any attribute.
+func selectStyledVoice(ranked []VoiceInfo, style voiceStyle, metadata func(VoiceInfo) voiceMetadata) (string, []string, bool) {
+	best, bestScore := -1, 0
+	var bestMatched []string
+	for i, voice := range ranked {
+		info := metadata(voice)
+		if info.Quality == "" {
+			info.Quality = voice.Quality
+		}
+		score, matched := style.match(info)
+		if len(matched) > 0 && (best < 0 || score > bestScore) {
+			best, bestScore, bestMatched = i, score, matched
+		}
+	}
+	if best < 0 || bestScore <= 0 {
+		return "", nil, false
+	}
+	return ranked[best].Name, bestMatched, true
+}
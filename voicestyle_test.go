@@ -0,0 +1,168 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// TestParseVoiceStyle tests reading attributes from free-form hints
+func TestParseVoiceStyle(t *testing.T) {
+	tests := []struct {
+		hint     string
+		expected voiceStyle
+	}{
+		{hint: "a calm female voice", expected: voiceStyle{Gender: genderFemale, Styles: []string{"calm"}}},
+		{hint: "Soothing, gentle older man", expected: voiceStyle{Gender: genderMale, Age: ageSenior, Styles: []string{"calm"}}},
+		{hint: "natural sounding professional voice", expected: voiceStyle{Styles: []string{"serious"}, Quality: true}},
+		{hint: "something nice", expected: voiceStyle{}},
+	}
+
+	for _, tt := range tests {
+		if got := parseVoiceStyle(tt.hint); !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("parseVoiceStyle(%q) = %+v, want %+v", tt.hint, got, tt.expected)
+		}
+	}
+}
+
+// TestVoiceMetadataTable_Lookup tests matching variants, piper models and backend defaults
+func TestVoiceMetadataTable_Lookup(t *testing.T) {
+	table := mergeVoiceMetadata(builtinVoiceMetadata, voiceMetadataTable{
+		"*":   {"custom-voice": {Gender: genderFemale, Styles: []string{"warm"}}},
+		"say": {"Alex": {Gender: genderMale, Styles: []string{"serious"}}},
+	})
+
+	tests := []struct {
+		name     string
+		backend  string
+		voice    string
+		expected voiceMetadata
+		found    bool
+	}{
+		{name: "quality_variant", backend: "say", voice: "Kyoko (Enhanced)", expected: builtinVoiceMetadata["say"]["Kyoko"], found: true},
+		{name: "user_override", backend: "say", voice: "Alex", expected: voiceMetadata{Gender: genderMale, Styles: []string{"serious"}}, found: true},
+		{name: "any_backend", backend: "piper", voice: "custom-voice", expected: voiceMetadata{Gender: genderFemale, Styles: []string{"warm"}}, found: true},
+		{
+			name: "piper_model", backend: "piper", voice: "en_US-lessac-high",
+			expected: voiceMetadata{Gender: genderFemale, Age: ageAdult, Styles: []string{"neutral", "calm"}, Quality: voiceQualityEnhanced}, found: true,
+		},
+		{name: "backend_default", backend: "espeak-ng", voice: "en-us", expected: builtinVoiceMetadata["espeak-ng"]["*"], found: true},
+		{name: "unknown", backend: "say", voice: "Nobody", expected: voiceMetadata{}, found: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, found := table.Lookup(tt.backend, tt.voice)
+			if !reflect.DeepEqual(got, tt.expected) || found != tt.found {
+				t.Errorf("Lookup(%q, %q) = %+v, %v, want %+v, %v", tt.backend, tt.voice, got, found, tt.expected, tt.found)
+			}
+		})
+	}
+}
+
+// newStyleTestVoiceSystem returns a say voice system with a mixed English and Japanese catalog
+func newStyleTestVoiceSystem() *VoiceSystem {
+	vs := &VoiceSystem{backend: &fakeBackend{name: "say"}, availableVoices: make(map[string]VoiceInfo)}
+	for _, voice := range []VoiceInfo{
+		{Name: "Samantha", Language: "en", Locale: "en_US", Quality: voiceQualityDefault},
+		{Name: "Zoe (Premium)", Language: "en", Locale: "en_US", Quality: voiceQualityPremium},
+		{Name: "Alex", Language: "en", Locale: "en_US", Quality: voiceQualityDefault},
+		{Name: "Daniel", Language: "en", Locale: "en_GB", Quality: voiceQualityDefault},
+		{Name: "Bubbles", Language: "en", Locale: "en_US"},
+		{Name: "Kyoko", Language: "ja", Locale: "ja_JP", Quality: voiceQualityDefault},
+		{Name: "Otoya", Language: "ja", Locale: "ja_JP", Quality: voiceQualityDefault},
+	} {
+		vs.availableVoices[voice.Name] = voice
+	}
+	return vs
+}
+
+// TestVoiceSystem_SelectVoiceForStyle tests resolving style hints against the installed catalog
+func TestVoiceSystem_SelectVoiceForStyle(t *testing.T) {
+	vs := newStyleTestVoiceSystem()
+
+	tests := []struct {
+		name     string
+		hint     string
+		language string
+		expected string
+		matched  []string
+	}{
+		{name: "calm_female", hint: "a calm female voice", language: "en", expected: "Zoe (Premium)", matched: []string{genderFemale, "calm"}},
+		{name: "male_ranked", hint: "male", language: "en", expected: "Alex", matched: []string{genderMale}},
+		{name: "serious_male", hint: "serious man", language: "en", expected: "Daniel", matched: []string{genderMale, "serious"}},
+		{name: "novelty_on_request", hint: "something fun", language: "en", expected: "Bubbles", matched: []string{styleNovelty}},
+		{name: "language_first", hint: "a calm male voice", language: "ja", expected: "Otoya", matched: []string{genderMale, "calm"}},
+		{name: "partial_match", hint: "elderly woman", language: "ja", expected: "Kyoko", matched: []string{genderFemale}},
+		{name: "nothing_matches", hint: "robotic", language: "ja", expected: ""},
+		{name: "not_understood", hint: "surprise me", language: "en", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			voice, matched := vs.SelectVoiceForStyle(tt.hint, tt.language)
+			if voice != tt.expected || !reflect.DeepEqual(matched, tt.matched) {
+				t.Errorf("SelectVoiceForStyle(%q, %q) = %q, %v, want %q, %v", tt.hint, tt.language, voice, matched, tt.expected, tt.matched)
+			}
+		})
+	}
+}
+
+// TestLoadVoiceMetadata tests adding entries for custom voices from a file
+func TestLoadVoiceMetadata(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "voices.json")
+	if err := os.WriteFile(path, []byte(`{"piper": {"en_US-mycompany": {"gender": "male", "styles": ["warm"]}}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("VOICE_NOTIFY_VOICE_METADATA", path)
+
+	table := loadVoiceMetadata()
+	if got, found := table.Lookup("piper", "en_US-mycompany-medium"); !found || got.Gender != genderMale {
+		t.Errorf("Lookup() = %+v, %v, want the custom entry", got, found)
+	}
+	if _, found := table.Lookup("say", "Samantha"); !found {
+		t.Error("Lookup(Samantha) not found, want the built-in entries kept")
+	}
+}
+
+// TestHandleNotifyVoice_VoiceStyle tests the voice_style parameter and its fallback warning
+func TestHandleNotifyVoice_VoiceStyle(t *testing.T) {
+	vs := newStyleTestVoiceSystem()
+	backend := &fakeBackend{name: "say"}
+	vs.backend = backend
+	langDetect := &LanguageDetector{autoDetect: true, defaultLanguage: "en"}
+
+	result, err := handleNotifyVoice(context.Background(), newTestToolRequest(map[string]any{
+		"message":     "Build finished",
+		"voice_style": "a serious male voice",
+	}), vs, langDetect, nil, newTestNotifier(), newSpeechJobTracker())
+	if err != nil || result.IsError {
+		t.Fatalf("handleNotifyVoice() = %+v, %v", result, err)
+	}
+	if len(backend.spoken) != 1 || backend.spoken[0].Voice != "Daniel" {
+		t.Errorf("spoken = %+v, want Daniel", backend.spoken)
+	}
+	if text := resultText(t, result); !strings.Contains(text, "Voice style: a serious male voice (matched male, serious)") {
+		t.Errorf("response %q does not report the style match", text)
+	}
+
+	result, err = handleNotifyVoice(context.Background(), newTestToolRequest(map[string]any{
+		"message":     "ビルドが完了しました",
+		"voice_style": "robotic",
+	}), vs, langDetect, nil, newTestNotifier(), newSpeechJobTracker())
+	if err != nil || result.IsError {
+		t.Fatalf("handleNotifyVoice() = %+v, %v", result, err)
+	}
+	if len(backend.spoken) != 2 || backend.spoken[1].Voice != "Kyoko" {
+		t.Errorf("spoken = %+v, want the ranked Japanese voice", backend.spoken)
+	}
+	warning, _ := mcp.AsTextContent(result.Content[len(result.Content)-1])
+	if len(result.Content) != 2 || warning == nil || !strings.Contains(warning.Text, "voice_style_unmatched") {
+		t.Errorf("result content = %+v, want a voice_style_unmatched warning", result.Content)
+	}
+}